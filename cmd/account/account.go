@@ -3,10 +3,15 @@ package account
 import (
 	"fmt"
 
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 )
 
+// showPrivateKey is bound to --show-private-key. Private keys are left out
+// of the output by default so they do not end up in logs or scripts.
+var showPrivateKey bool
+
 var AccountCmd = &cobra.Command{
 	Use:   "account",
 	Short: "Accont is a palette that contains account based commands",
@@ -16,14 +21,52 @@ var AccountCmd = &cobra.Command{
 	},
 }
 
-func printAccount(account wallet.Account) {
+// accountOutput is the json/yaml structure of an account.
+type accountOutput struct {
+	Label       string              `json:"label" yaml:"label"`
+	Address     string              `json:"address" yaml:"address"`
+	PrivateKey  string              `json:"privateKey,omitempty" yaml:"privateKey,omitempty"`
+	Tokens      []string            `json:"tokens" yaml:"tokens"`
+	MultiTokens map[string][]string `json:"erc1155,omitempty" yaml:"erc1155,omitempty"`
+	Selected    bool                `json:"selected" yaml:"selected"`
+	// Explorer links to the address on the selected network, if it has a
+	// block explorer
+	Explorer string `json:"explorer,omitempty" yaml:"explorer,omitempty"`
+}
+
+func newAccountOutput(account wallet.Account) accountOutput {
+	tokens := account.Tokens
+	if tokens == nil {
+		tokens = []string{}
+	}
+	result := accountOutput{
+		Label:       account.Label,
+		Address:     account.Publicy,
+		Tokens:      tokens,
+		MultiTokens: account.MultiTokens,
+		Selected:    account.Selected,
+	}
+	if showPrivateKey {
+		result.PrivateKey = account.Privatey
+	}
+	return result
+}
+
+func printAccount(account wallet.Account, explorer string) {
 	if account.Selected {
 		fmt.Println("Label:", account.Label, "(Selected)")
 	} else {
 		fmt.Println("Label:", account.Label)
 	}
 	fmt.Println("Address: ", account.Publicy)
-	fmt.Println("Private Key: ", account.Privatey)
+	if showPrivateKey {
+		fmt.Println("Private Key: ", account.Privatey)
+	}
 	fmt.Println("Tokens: ", account.Tokens)
+	output.PrintLink(explorer)
 	fmt.Println("------------------------------------------------------------------------------------------")
 }
+
+func init() {
+	AccountCmd.PersistentFlags().BoolVar(&showPrivateKey, "show-private-key", false, "Include private keys in the output")
+}
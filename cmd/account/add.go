@@ -2,9 +2,8 @@ package account
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,25 +13,30 @@ var (
 	label string
 )
 
-func createAccount() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+func createAccount() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	fmt.Println(label)
-	account, err := wallet.CreateNewAccount(db, label)
+	defer store.Close()
+
+	account, err := wallet.CreateNewAccount(store, label)
 	if err != nil {
-		log.Fatalf("Failed to create account: %v", err)
+		return fmt.Errorf("failed to create account: %w", err)
 	}
-	printAccount(account)
+	account.Selected = true
+
+	return output.Print(newAccountOutput(account), func() {
+		printAccount(account, "")
+	})
 }
 
 var createCmd = &cobra.Command{
 	Use:   "create",
 	Short: "Add a new account to the wallet",
 	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		createAccount()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createAccount()
 	},
 }
 
@@ -1,51 +1,115 @@
 package account
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"math/big"
+	"os"
+	"os/signal"
 
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
 	"github.com/EliasManj/go-wallet/utils"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-func showBalance() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+var (
+	balance_unit      string
+	balance_precision int
+	balance_watch     bool
+)
+
+// balanceOutput is the json/yaml structure of a balance. Wei is the exact
+// balance, Balance the same amount formatted in Unit.
+type balanceOutput struct {
+	Account string `json:"account" yaml:"account"`
+	Address string `json:"address" yaml:"address"`
+	Network string `json:"network" yaml:"network"`
+	Symbol  string `json:"symbol" yaml:"symbol"`
+	Wei     string `json:"wei" yaml:"wei"`
+	Balance string `json:"balance" yaml:"balance"`
+	Unit    string `json:"unit" yaml:"unit"`
+	// Explorer links to the address when the network has a block explorer
+	Explorer string `json:"explorer,omitempty" yaml:"explorer,omitempty"`
+}
+
+func showBalance() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	account, err := wallet.GetSelectedAccount(db)
+	account, err := selection.GetAccount(store)
 	if err != nil {
-		log.Fatalf("Failed to get account: %v", err)
+		return fmt.Errorf("failed to get account: %w", err)
 	}
 
-	network, err := wallet.GetSelectedNetwork(db)
+	network, err := selection.GetNetwork(store)
 	if err != nil {
-		log.Fatalf("Failed to get network: %v", err)
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	if balance_watch {
+		// Release the database while watching
+		store.Close()
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return wallet.WatchBalance(ctx, account.Publicy, network, func(balance *big.Int) error {
+			return printBalance(account, network, balance)
+		})
 	}
 
 	balance, err := wallet.GetBalance(account.Publicy, network)
 	if err != nil {
-		log.Fatalf("Failed to get balance: %v", err)
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	return printBalance(account, network, balance)
+}
+
+func printBalance(account wallet.Account, network wallet.Network, balance *big.Int) error {
+	formatted, err := utils.FormatAmount(balance, balance_unit, balance_precision)
+	if err != nil {
+		return fmt.Errorf("failed to format balance: %w", err)
 	}
 
-	fmt.Printf("Balance: %s %s", balance.String(), network.Symbol)
-	fmt.Println()
+	result := balanceOutput{
+		Account: account.Label,
+		Address: account.Publicy,
+		Network: network.Label,
+		Symbol:  network.Symbol,
+		Wei:     balance.String(),
+		Balance: formatted,
+		Unit:    balance_unit,
 
+		Explorer: network.AddressUrl(account.Publicy),
+	}
+	return output.Print(result, func() {
+		fmt.Printf("Balance: %s %s", formatted, utils.UnitLabel(balance_unit, network.Symbol))
+		fmt.Println()
+		output.PrintLink(result.Explorer)
+	})
 }
 
 var balanceCmd = &cobra.Command{
 	Use:   "balance",
 	Short: "This command displays the balance of the selected account",
-	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		showBalance()
+	Long: `Displays the balance of the selected account on the selected network.
+
+With --watch the balance is printed again every time it changes, until
+interrupted. WebSocket and IPC endpoints are subscribed to new blocks;
+HTTP endpoints are polled.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showBalance()
 	},
 }
 
 func init() {
 	AccountCmd.AddCommand(balanceCmd)
+	balanceCmd.Flags().StringVarP(&balance_unit, "unit", "u", "ether", "Unit to display the balance in: wei, gwei or ether")
+	balanceCmd.Flags().IntVarP(&balance_precision, "precision", "p", -1, "Number of decimals to display, all significant decimals when negative")
+	balanceCmd.Flags().BoolVarP(&balance_watch, "watch", "w", false, "Keep running and print the balance whenever it changes")
 }
@@ -2,9 +2,8 @@ package account
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -15,23 +14,21 @@ var (
 	import_key   string
 )
 
-func importAccount() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+func importAccount() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	_, err = wallet.GetAccount(db, import_label)
+	_, err = wallet.GetAccount(store, import_label)
 	if err == nil {
-		fmt.Printf("Account with label %s already exists\n", import_label)
-		return
+		return fmt.Errorf("%w: %s", wallet.ErrAccountExists, import_label)
 	}
 
 	public, err := wallet.GetAddressFromPrivateKey(import_key)
 	if err != nil {
-		fmt.Printf("Failed to get public key from private key: %v\n", err)
-		return
+		return fmt.Errorf("failed to get public key from private key: %w", err)
 	}
 
 	account := wallet.Account{
@@ -40,21 +37,23 @@ func importAccount() {
 		Publicy:  public,
 	}
 
-	err = wallet.ImportAccount(db, account)
+	err = wallet.ImportAccount(store, account)
 	if err != nil {
-		fmt.Printf("Failed to import account: %v\n", err)
-		return
+		return fmt.Errorf("failed to import account: %w", err)
 	}
+	account.Selected = true
 
-	fmt.Printf("Account with label %s imported successfully\n", import_label)
+	return output.Print(newAccountOutput(account), func() {
+		fmt.Printf("Account with label %s imported successfully\n", import_label)
+	})
 }
 
 var ImportCmd = &cobra.Command{
 	Use:   "import",
 	Short: "This import all the networks available for the wallet",
 	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		importAccount()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importAccount()
 	},
 }
 
@@ -2,39 +2,62 @@ package account
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-func listAccounts() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+var (
+	list_archived bool
+)
+
+func listAccounts() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	listAccounts, title := wallet.ListAccounts, "Accounts:"
+	if list_archived {
+		listAccounts, title = wallet.ListArchivedAccounts, "Archived accounts:"
 	}
-	accounts, err := wallet.ListAccounts(db)
+	accounts, err := listAccounts(store)
 	if err != nil {
-		log.Fatalf("Failed to list accounts: %v", err)
+		return fmt.Errorf("failed to list accounts: %w", err)
 	}
-	fmt.Println("Accounts:")
-	fmt.Println("")
+
+	// Addresses link to the block explorer of the selected network, if any
+	network, _ := selection.GetNetwork(store)
+
+	result := make([]accountOutput, 0, len(accounts))
 	for _, account := range accounts {
-		printAccount(account)
+		entry := newAccountOutput(account)
+		entry.Explorer = network.AddressUrl(account.Publicy)
+		result = append(result, entry)
 	}
+	return output.Print(result, func() {
+		fmt.Println(title)
+		fmt.Println("")
+		for i, account := range accounts {
+			printAccount(account, result[i].Explorer)
+		}
+	})
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "This list all the networks available for the wallet",
 	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		listAccounts()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listAccounts()
 	},
 }
 
 func init() {
 	AccountCmd.AddCommand(listCmd)
+	listCmd.Flags().BoolVar(&list_archived, "archived", false, "List the accounts archived with rm --archive")
 }
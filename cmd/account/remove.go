@@ -2,49 +2,86 @@ package account
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	label_remove string
+	label_remove   string
+	remove_force   bool
+	remove_archive bool
 )
 
-func removeAccount() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+func removeAccount() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	account, err := wallet.GetAccount(db, label_remove)
+	account, err := wallet.GetAccount(store, label_remove)
 	if err != nil {
-		log.Fatalf("Failed to get account: %v", err)
+		return fmt.Errorf("failed to get account: %w", err)
 	}
 
-	err = wallet.RemoveAccount(db, account.Label)
+	if account.Selected && !remove_force {
+		return fmt.Errorf("account %s is selected, use --force to remove it", account.Label)
+	}
+
+	if remove_archive {
+		err = wallet.ArchiveAccount(store, account.Label)
+		if err != nil {
+			return fmt.Errorf("failed to archive account: %w", err)
+		}
+		return output.Print(map[string]string{"archived": account.Label}, func() {
+			fmt.Printf("Account %s archived\n", account.Label)
+		})
+	}
+
+	// Deleting the private key loses the funds for good
+	if !remove_force {
+		err = wallet.CheckEmptyAccount(store, account)
+		if err != nil {
+			return fmt.Errorf("refusing to delete account %s, use --archive to keep its key or --force: %w", account.Label, err)
+		}
+	}
+
+	err = wallet.RemoveAccount(store, account.Label)
 	if err != nil {
-		log.Fatalf("Failed to delete account: %v", err)
+		return fmt.Errorf("failed to delete account: %w", err)
 	}
 
-	fmt.Printf("Account %s deleted\n", account.Label)
+	return output.Print(map[string]string{"removed": account.Label}, func() {
+		fmt.Printf("Account %s deleted\n", account.Label)
+	})
 }
 
 var removeCmd = &cobra.Command{
 	Use:   "rm",
 	Short: "This command removes an account with the given label",
-	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		removeAccount()
+	Long: `Remove an account from the wallet.
+
+The selected account is only removed with --force, and another account is
+selected in its place. An account is only deleted once its balance and the
+balances of its tracked ERC-20 tokens are zero on every network; when a
+balance is found or cannot be checked, or the account tracks ERC-1155
+contracts whose holdings cannot be listed, the account is kept unless
+--force is given.
+
+With --archive the account is moved to the archive instead, keeping its
+private key: see account list --archived and account unarchive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return removeAccount()
 	},
 }
 
 func init() {
 	AccountCmd.AddCommand(removeCmd)
 	removeCmd.Flags().StringVarP(&label_remove, "label", "l", "", "Label for the account to be deleted")
+	removeCmd.Flags().BoolVarP(&remove_force, "force", "f", false, "Remove the account even if it is selected or holds funds")
+	removeCmd.Flags().BoolVar(&remove_archive, "archive", false, "Move the account to the archive instead of deleting it")
 	removeCmd.MarkFlagRequired("label")
 }
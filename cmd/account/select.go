@@ -2,9 +2,8 @@ package account
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,32 +13,34 @@ var (
 	label_select string
 )
 
-func selectAccount() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+func selectAccount() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	account, err := wallet.GetAccount(db, label_select)
+	account, err := wallet.GetAccount(store, label_select)
 	if err != nil {
-		log.Fatalf("Failed to get account: %v", err)
+		return fmt.Errorf("failed to get account: %w", err)
 	}
 
-	err = wallet.SelectAccount(db, account.Label)
+	err = wallet.SelectAccount(store, account.Label)
 	if err != nil {
-		log.Fatalf("Failed to select account: %v", err)
+		return fmt.Errorf("failed to select account: %w", err)
 	}
 
-	fmt.Printf("Account %s selected\n", account.Label)
+	return output.Print(map[string]string{"selected": account.Label}, func() {
+		fmt.Printf("Account %s selected\n", account.Label)
+	})
 }
 
 var selectCmd = &cobra.Command{
 	Use:   "select",
 	Short: "This selects the specified account to be the active account",
 	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		selectAccount()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return selectAccount()
 	},
 }
 
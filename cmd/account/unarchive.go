@@ -0,0 +1,46 @@
+package account
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	label_unarchive string
+)
+
+func unarchiveAccount() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	err = wallet.UnarchiveAccount(store, label_unarchive)
+	if err != nil {
+		return fmt.Errorf("failed to unarchive account: %w", err)
+	}
+
+	return output.Print(map[string]string{"unarchived": label_unarchive}, func() {
+		fmt.Printf("Account %s restored from the archive\n", label_unarchive)
+	})
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive",
+	Short: "Restore an archived account",
+	Long:  `Move a account archived with rm --archive back into the wallet. It is not selected.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unarchiveAccount()
+	},
+}
+
+func init() {
+	AccountCmd.AddCommand(unarchiveCmd)
+	unarchiveCmd.Flags().StringVarP(&label_unarchive, "label", "l", "", "Label of the archived account")
+	unarchiveCmd.MarkFlagRequired("label")
+}
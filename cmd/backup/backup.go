@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	backup_out             string
+	backup_passphrase_file string
+)
+
+// backupOutput is the json/yaml structure of a written backup.
+type backupOutput struct {
+	File     string `json:"file" yaml:"file"`
+	Accounts int    `json:"accounts" yaml:"accounts"`
+	Networks int    `json:"networks" yaml:"networks"`
+}
+
+func backupWallet() error {
+	if _, err := os.Stat(backup_out); err == nil {
+		return fmt.Errorf("%s already exists", backup_out)
+	}
+
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	backup, err := wallet.NewBackup(store)
+	if err != nil {
+		return fmt.Errorf("failed to read wallet: %w", err)
+	}
+
+	passphrase, err := readPassphrase(backup_passphrase_file, true)
+	if err != nil {
+		return err
+	}
+
+	data, err := wallet.EncryptBackup(backup, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt backup: %w", err)
+	}
+
+	// O_EXCL so that an existing file is never replaced
+	file, err := os.OpenFile(backup_out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %w", err)
+	}
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(backup_out)
+		return fmt.Errorf("failed to write backup: %w", err)
+	}
+
+	result := backupOutput{File: backup_out, Accounts: len(backup.Accounts), Networks: len(backup.Networks)}
+	return output.Print(result, func() {
+		fmt.Printf("Backed up %d accounts and %d networks to %s\n", result.Accounts, result.Networks, backup_out)
+	})
+}
+
+var BackupCmd = &cobra.Command{
+	Use:   "backup",
+	Short: "Write an encrypted backup of the whole wallet",
+	Long: `Writes every account with its private key and tracked tokens, every network and the current
+selection to a single file encrypted with AES-256-GCM under a key derived from a passphrase with
+scrypt. The passphrase is read from --passphrase-file, WALLET_PASSPHRASE or the terminal.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return backupWallet()
+	},
+}
+
+func init() {
+	BackupCmd.Flags().StringVar(&backup_out, "out", "", "File to write the backup to, it must not exist")
+	BackupCmd.MarkFlagRequired("out")
+	BackupCmd.Flags().StringVar(&backup_passphrase_file, "passphrase-file", "", "File holding the passphrase")
+}
@@ -0,0 +1,68 @@
+package backup
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"golang.org/x/term"
+)
+
+// readPassphrase returns the passphrase from --passphrase-file, then
+// WALLET_PASSPHRASE, then asks for it on the terminal. New passphrases are
+// asked twice.
+func readPassphrase(file string, confirm bool) (string, error) {
+	if file != "" {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %w", err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+	if passphrase := os.Getenv("WALLET_PASSPHRASE"); passphrase != "" {
+		return passphrase, nil
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	passphrase, err := prompt(reader, "Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := prompt(reader, "Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != passphrase {
+			return "", fmt.Errorf("passphrases do not match")
+		}
+	}
+	if passphrase == "" {
+		return "", fmt.Errorf("passphrase must not be empty")
+	}
+	return passphrase, nil
+}
+
+// prompt asks question and reads the answer without echoing it when stdin
+// is a terminal, or reads a line of piped input otherwise.
+func prompt(reader *bufio.Reader, question string) (string, error) {
+	fmt.Fprint(output.Stderr, question)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		passphrase, err := term.ReadPassword(fd)
+		fmt.Fprintln(output.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase: %w", err)
+		}
+		return string(passphrase), nil
+	}
+
+	line, err := reader.ReadString('\n')
+	fmt.Fprintln(output.Stderr)
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read passphrase: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,113 @@
+package backup
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	restore_in              string
+	restore_passphrase_file string
+	restore_merge           bool
+	restore_on_conflict     string
+)
+
+// recordOutput is the json/yaml structure of a restored account or network.
+type recordOutput struct {
+	Label       string `json:"label" yaml:"label"`
+	BackupLabel string `json:"backupLabel" yaml:"backupLabel"`
+	Action      string `json:"action" yaml:"action"`
+}
+
+type restoreOutput struct {
+	Accounts         []recordOutput `json:"accounts" yaml:"accounts"`
+	Networks         []recordOutput `json:"networks" yaml:"networks"`
+	ArchivedAccounts []recordOutput `json:"archivedAccounts" yaml:"archivedAccounts"`
+	ArchivedNetworks []recordOutput `json:"archivedNetworks" yaml:"archivedNetworks"`
+}
+
+func newRecordOutputs(records []wallet.RestoredRecord) []recordOutput {
+	outputs := make([]recordOutput, 0, len(records))
+	for _, record := range records {
+		outputs = append(outputs, recordOutput{Label: record.Label, BackupLabel: record.BackupLabel, Action: record.Action})
+	}
+	return outputs
+}
+
+func printRecords(kind string, records []recordOutput) {
+	for _, record := range records {
+		if record.Label != record.BackupLabel {
+			fmt.Printf("%s %s: %s as %s\n", kind, record.BackupLabel, record.Action, record.Label)
+		} else {
+			fmt.Printf("%s %s: %s\n", kind, record.BackupLabel, record.Action)
+		}
+	}
+}
+
+func restoreWallet() error {
+	data, err := os.ReadFile(restore_in)
+	if err != nil {
+		return fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	passphrase, err := readPassphrase(restore_passphrase_file, false)
+	if err != nil {
+		return err
+	}
+
+	// The backup is decrypted and validated before the database is touched
+	backup, err := wallet.DecryptBackup(data, passphrase)
+	if err != nil {
+		return fmt.Errorf("failed to open backup: %w", err)
+	}
+
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	restored, err := wallet.RestoreBackup(store, backup, wallet.RestoreOptions{Merge: restore_merge, OnConflict: restore_on_conflict})
+	if err != nil {
+		return fmt.Errorf("failed to restore backup: %w", err)
+	}
+
+	result := restoreOutput{
+		Accounts:         newRecordOutputs(restored.Accounts),
+		Networks:         newRecordOutputs(restored.Networks),
+		ArchivedAccounts: newRecordOutputs(restored.ArchivedAccounts),
+		ArchivedNetworks: newRecordOutputs(restored.ArchivedNetworks),
+	}
+	return output.Print(result, func() {
+		printRecords("Network", result.Networks)
+		printRecords("Archived network", result.ArchivedNetworks)
+		printRecords("Account", result.Accounts)
+		printRecords("Archived account", result.ArchivedAccounts)
+		fmt.Printf("Restored %s created %s\n", restore_in, backup.Created.Format("2006-01-02 15:04:05 MST"))
+	})
+}
+
+var RestoreCmd = &cobra.Command{
+	Use:   "restore",
+	Short: "Restore the wallet from an encrypted backup",
+	Long: `Decrypts a backup written by the backup command and checks its integrity before writing
+anything. Without --merge the wallet must be empty. With --merge the backup is added to the
+existing accounts and networks, and --on-conflict decides what happens to a label that exists
+with different content: fail (the default, nothing is written), skip, overwrite or rename.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return restoreWallet()
+	},
+}
+
+func init() {
+	RestoreCmd.Flags().StringVar(&restore_in, "in", "", "Backup file to restore")
+	RestoreCmd.MarkFlagRequired("in")
+	RestoreCmd.Flags().StringVar(&restore_passphrase_file, "passphrase-file", "", "File holding the passphrase")
+	RestoreCmd.Flags().BoolVar(&restore_merge, "merge", false, "Merge the backup into a wallet that is not empty")
+	RestoreCmd.Flags().StringVar(&restore_on_conflict, "on-conflict", wallet.ConflictFail, "With --merge, what to do with labels that exist: fail, skip, overwrite or rename")
+}
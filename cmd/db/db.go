@@ -0,0 +1,15 @@
+package db
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var DbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "Db is a palette that contains commands to inspect and migrate the wallet database",
+	Long: `The database records the version of its layout. Opening it with any other command migrates
+it to the version of this wallet, these commands show and apply the migrations explicitly.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
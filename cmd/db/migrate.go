@@ -0,0 +1,70 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	migrate_dry_run bool
+)
+
+// migrationOutput is the json/yaml structure of a migration.
+type migrationOutput struct {
+	Version     int    `json:"version" yaml:"version"`
+	Description string `json:"description" yaml:"description"`
+	Records     int    `json:"records" yaml:"records"`
+}
+
+func migrate() error {
+	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	results, err := wallet.Migrate(db, migrate_dry_run)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %w", err)
+	}
+
+	migrations := make([]migrationOutput, 0, len(results))
+	for _, result := range results {
+		migrations = append(migrations, migrationOutput(result))
+	}
+	data := map[string]interface{}{"dryRun": migrate_dry_run, "version": wallet.SchemaVersion, "migrations": migrations}
+	return output.Print(data, func() {
+		if len(results) == 0 {
+			fmt.Printf("Database is up to date at version %d\n", wallet.SchemaVersion)
+			return
+		}
+		for _, result := range results {
+			fmt.Printf("Version %d: %s (%d records)\n", result.Version, result.Description, result.Records)
+		}
+		if migrate_dry_run {
+			fmt.Println("Dry run, nothing was written")
+		} else {
+			fmt.Printf("Database migrated to version %d\n", wallet.SchemaVersion)
+		}
+	})
+}
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "This migrates the database to the schema version of this wallet",
+	Long: `Applies every pending migration in a single transaction, so the database is either fully
+migrated or left untouched. With --dry-run the migrations are listed without writing anything.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return migrate()
+	},
+}
+
+func init() {
+	DbCmd.AddCommand(migrateCmd)
+	migrateCmd.Flags().BoolVar(&migrate_dry_run, "dry-run", false, "List the pending migrations without applying them")
+}
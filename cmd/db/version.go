@@ -0,0 +1,56 @@
+package db
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// versionOutput is the json/yaml structure of the schema version of the
+// database and the version this wallet writes.
+type versionOutput struct {
+	Database  string `json:"database" yaml:"database"`
+	Version   int    `json:"version" yaml:"version"`
+	Supported int    `json:"supported" yaml:"supported"`
+}
+
+func showVersion() error {
+	path := viper.GetString("database_file_path")
+	db, err := utils.OpenDB(path)
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	version, err := wallet.GetSchemaVersion(db)
+	if err != nil {
+		return fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	result := versionOutput{Database: path, Version: version, Supported: wallet.SchemaVersion}
+	return output.Print(result, func() {
+		fmt.Println("Database:", path)
+		fmt.Println("Schema version:", version)
+		fmt.Println("Supported version:", wallet.SchemaVersion)
+		if version > wallet.SchemaVersion {
+			fmt.Println("The database was written by a newer wallet and cannot be opened")
+		}
+	})
+}
+
+var versionCmd = &cobra.Command{
+	Use:   "version",
+	Short: "This displays the schema version of the database",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showVersion()
+	},
+}
+
+func init() {
+	DbCmd.AddCommand(versionCmd)
+}
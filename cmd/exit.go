@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/EliasManj/go-wallet/cmd/profile"
+	"github.com/EliasManj/go-wallet/wallet"
+)
+
+// Process exit codes. They are part of the interface scripts rely on, so
+// existing values must never change.
+const (
+	ExitOK                  = 0
+	ExitFailure             = 1  // any error without a more specific code
+	ExitUsage               = 2  // invalid flags or flag values
+	ExitAccountNotFound     = 3  // the account does not exist or none is selected
+	ExitNetworkNotFound     = 4  // the network does not exist or none is selected
+	ExitAlreadyExists       = 5  // an account, network or profile with the name already exists
+	ExitInsufficientFunds   = 6  // the balance does not cover the amount and fees
+	ExitChainIDMismatch     = 7  // the RPC node reports a different chain id
+	ExitTransactionReverted = 8  // the transaction was mined but failed
+	ExitDatabase            = 9  // the database is missing data or is too new
+	ExitProfileNotFound     = 10 // the profile does not exist
+	ExitAccountHasBalance   = 11 // the account to remove still holds funds
+	ExitSecretNotFound      = 12 // a secret reference of the network cannot be resolved
+)
+
+const exitCodesHelp = `Exit codes:
+  0  success
+  1  failure without a more specific code
+  2  invalid flags or flag values
+  3  account not found or no account selected
+  4  network not found or no network selected
+  5  an account, network or profile with the name already exists
+  6  insufficient funds
+  7  chain id mismatch between the network and its RPC node
+  8  transaction reverted
+  9  database not initialized or written by a newer wallet
+  10 profile not found
+  11 the account to remove still holds funds
+  12 a secret reference of the network cannot be resolved`
+
+// usageError marks errors in the command line itself.
+type usageError struct {
+	err error
+}
+
+func (e usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e usageError) Unwrap() error {
+	return e.err
+}
+
+// exitCode maps the error a command failed with to its exit code.
+func exitCode(err error) int {
+	var usage usageError
+	switch {
+	case err == nil:
+		return ExitOK
+	case errors.As(err, &usage):
+		return ExitUsage
+	case errors.Is(err, wallet.ErrAccountNotFound), errors.Is(err, wallet.ErrAccountNotSelected):
+		return ExitAccountNotFound
+	case errors.Is(err, wallet.ErrNetworkNotFound), errors.Is(err, wallet.ErrNetworkNotSelected):
+		return ExitNetworkNotFound
+	case errors.Is(err, wallet.ErrAccountExists), errors.Is(err, wallet.ErrNetworkExists), errors.Is(err, profile.ErrExists):
+		return ExitAlreadyExists
+	case errors.Is(err, wallet.ErrInsufficientFunds):
+		return ExitInsufficientFunds
+	case errors.Is(err, wallet.ErrChainIDMismatch):
+		return ExitChainIDMismatch
+	case errors.Is(err, wallet.ErrTransactionReverted):
+		return ExitTransactionReverted
+	case errors.Is(err, wallet.ErrBucketNotFound), errors.Is(err, wallet.ErrSchemaTooNew):
+		return ExitDatabase
+	case errors.Is(err, profile.ErrNotFound):
+		return ExitProfileNotFound
+	case errors.Is(err, wallet.ErrAccountHasBalance):
+		return ExitAccountHasBalance
+	case errors.Is(err, wallet.ErrSecretNotFound):
+		return ExitSecretNotFound
+	}
+	return ExitFailure
+}
@@ -0,0 +1,102 @@
+package multitoken
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	balance_contract string
+	balance_ids      []string
+)
+
+// balancesOutput is the json/yaml structure of the balances held in one
+// ERC-1155 contract, keyed by token id.
+type balancesOutput struct {
+	Contract string            `json:"contract" yaml:"contract"`
+	Balances map[string]string `json:"balances" yaml:"balances"`
+	Explorer string            `json:"explorer,omitempty" yaml:"explorer,omitempty"`
+}
+
+func showBalances() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if balance_contract != "" && !common.IsHexAddress(balance_contract) {
+		return fmt.Errorf("invalid contract address: %s", balance_contract)
+	}
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	ids, err := parseIds(balance_ids)
+	if err != nil {
+		return fmt.Errorf("failed to parse token ids: %w", err)
+	}
+
+	// Without --contract every contract tracked on the selected network is queried
+	contracts := account.MultiTokens[network.Label]
+	if balance_contract != "" {
+		contracts = []string{balance_contract}
+	}
+	if len(contracts) == 0 {
+		return fmt.Errorf("no ERC-1155 contracts tracked on network %s, pass --contract", network.Label)
+	}
+
+	result := make([]balancesOutput, 0, len(contracts))
+	for _, contract := range contracts {
+		balances, err := wallet.GetMultiTokenBalances(contract, account.Publicy, ids, network)
+		if err != nil {
+			return fmt.Errorf("failed to get balances of %s: %w", contract, err)
+		}
+
+		entry := balancesOutput{Contract: contract, Balances: make(map[string]string), Explorer: network.TokenUrl(contract)}
+		for i, id := range ids {
+			entry.Balances[id.String()] = balances[i].String()
+		}
+		result = append(result, entry)
+	}
+
+	return output.Print(result, func() {
+		for _, entry := range result {
+			fmt.Println("Contract:", entry.Contract)
+			for _, id := range ids {
+				fmt.Printf("Token ID %s: %s\n", id, entry.Balances[id.String()])
+			}
+			output.PrintLink(entry.Explorer)
+			fmt.Println("------------------------------------------------------------------------------------------")
+		}
+	})
+}
+
+var balanceCmd = &cobra.Command{
+	Use:   "balance",
+	Short: "This displays the ERC-1155 balances of the selected account for a set of token ids",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showBalances()
+	},
+}
+
+func init() {
+	MultiTokenCmd.AddCommand(balanceCmd)
+	balanceCmd.Flags().StringVarP(&balance_contract, "contract", "c", "", "Address of the ERC-1155 contract, defaults to every tracked contract")
+	balanceCmd.Flags().StringSliceVarP(&balance_ids, "ids", "i", nil, "Comma separated token ids to query")
+	balanceCmd.MarkFlagRequired("ids")
+}
@@ -0,0 +1,42 @@
+package multitoken
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/EliasManj/go-wallet/utils"
+	"github.com/spf13/cobra"
+)
+
+var MultiTokenCmd = &cobra.Command{
+	Use:   "erc1155",
+	Short: "Erc1155 is a palette that contains ERC-1155 multi-token based commands",
+	Long:  `Track ERC-1155 contracts, query balances for a set of token ids and send single or batch transfers from the selected account`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func parseIds(values []string) ([]*big.Int, error) {
+	parsed := make([]*big.Int, 0, len(values))
+	for _, value := range values {
+		n, ok := new(big.Int).SetString(value, 0)
+		if !ok || n.Sign() < 0 {
+			return nil, fmt.Errorf("invalid token id %q", value)
+		}
+		parsed = append(parsed, n)
+	}
+	return parsed, nil
+}
+
+func parseAmounts(values []string) ([]*big.Int, error) {
+	parsed := make([]*big.Int, 0, len(values))
+	for _, value := range values {
+		amount, err := utils.ParseTokenAmount(value)
+		if err != nil {
+			return nil, err
+		}
+		parsed = append(parsed, amount)
+	}
+	return parsed, nil
+}
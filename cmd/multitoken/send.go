@@ -0,0 +1,113 @@
+package multitoken
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	send_contract string
+	send_to       string
+	send_ids      []string
+	send_amounts  []string
+)
+
+// sendOutput is the json/yaml structure of an ERC-1155 transfer. The
+// transaction is sent to the contract, Recipient receives the tokens and
+// Amounts is keyed by token id.
+type sendOutput struct {
+	output.Transaction `yaml:",inline"`
+	Recipient          string            `json:"recipient" yaml:"recipient"`
+	Amounts            map[string]string `json:"amounts" yaml:"amounts"`
+}
+
+func sendTokens() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if !common.IsHexAddress(send_contract) {
+		return fmt.Errorf("invalid contract address: %s", send_contract)
+	}
+	if !common.IsHexAddress(send_to) {
+		return fmt.Errorf("invalid recipient address: %s", send_to)
+	}
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	ids, err := parseIds(send_ids)
+	if err != nil {
+		return fmt.Errorf("failed to parse token ids: %w", err)
+	}
+
+	amounts, err := parseAmounts(send_amounts)
+	if err != nil {
+		return fmt.Errorf("failed to parse amounts: %w", err)
+	}
+
+	output.Info("Sending from account %s on network %s", account.Label, network.Label)
+
+	tx, err := wallet.SendMultiToken(account.Privatey, send_contract, send_to, ids, amounts, network)
+	if err != nil {
+		return fmt.Errorf("failed to send tokens: %w", err)
+	}
+
+	result := sendOutput{
+		Transaction: output.NewTransaction(tx),
+		Recipient:   send_to,
+		Amounts:     make(map[string]string),
+	}
+	for i, id := range ids {
+		result.Amounts[id.String()] = amounts[i].String()
+	}
+	return output.Print(result, func() {
+		fmt.Printf("Transaction hash: %s\n", tx.Hash)
+		fmt.Printf("Contract: %s\n", tx.To)
+		fmt.Printf("From: %s\n", tx.From)
+		fmt.Printf("To: %s\n", send_to)
+		for i, id := range ids {
+			fmt.Printf("Token ID %s: %s\n", id, amounts[i])
+		}
+		fmt.Printf("Gas: %s\n", tx.GasUsed)
+		fmt.Printf("Gas price: %s\n", tx.GasPrice)
+		output.PrintLink(tx.Network.TransactionUrl(tx.Hash))
+	})
+}
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send ERC-1155 tokens from the selected account",
+	Long: `Send ERC-1155 tokens from the selected account. A single id is sent with safeTransferFrom,
+several ids (with one amount each) are sent in one safeBatchTransferFrom transaction.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendTokens()
+	},
+}
+
+func init() {
+	MultiTokenCmd.AddCommand(sendCmd)
+	sendCmd.Flags().StringVarP(&send_contract, "contract", "c", "", "Address of the ERC-1155 contract")
+	sendCmd.MarkFlagRequired("contract")
+	sendCmd.Flags().StringVarP(&send_to, "to", "t", "", "Address to send the tokens to")
+	sendCmd.MarkFlagRequired("to")
+	sendCmd.Flags().StringSliceVarP(&send_ids, "ids", "i", nil, "Comma separated token ids to send")
+	sendCmd.MarkFlagRequired("ids")
+	sendCmd.Flags().StringSliceVarP(&send_amounts, "amts", "a", nil, "Comma separated amounts, one per token id")
+	sendCmd.MarkFlagRequired("amts")
+}
@@ -0,0 +1,117 @@
+package multitoken
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	track_contract string
+)
+
+// trackedOutput is the json/yaml structure of the ERC-1155 contracts tracked
+// by an account on a network.
+type trackedOutput struct {
+	Account   string   `json:"account" yaml:"account"`
+	Network   string   `json:"network" yaml:"network"`
+	Contracts []string `json:"contracts" yaml:"contracts"`
+}
+
+func trackContract() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if !common.IsHexAddress(track_contract) {
+		return fmt.Errorf("invalid contract address: %s", track_contract)
+	}
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	contract := common.HexToAddress(track_contract).String()
+	err = wallet.AddMultiTokenToAccount(store, account.Label, network.Label, contract)
+	if err != nil {
+		return fmt.Errorf("failed to track contract: %w", err)
+	}
+
+	result := trackedOutput{
+		Account:   account.Label,
+		Network:   network.Label,
+		Contracts: append(account.MultiTokens[network.Label], contract),
+	}
+	return output.Print(result, func() {
+		fmt.Printf("Contract %s tracked for account %s on network %s\n", contract, account.Label, network.Label)
+	})
+}
+
+func listTracked() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	result := trackedOutput{
+		Account:   account.Label,
+		Network:   network.Label,
+		Contracts: append([]string{}, account.MultiTokens[network.Label]...),
+	}
+	return output.Print(result, func() {
+		fmt.Printf("ERC-1155 contracts of %s on %s:\n", account.Label, network.Label)
+		fmt.Println("")
+		for _, contract := range result.Contracts {
+			fmt.Println(contract)
+		}
+	})
+}
+
+var trackCmd = &cobra.Command{
+	Use:   "track",
+	Short: "This tracks an ERC-1155 contract for the selected account and network",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return trackContract()
+	},
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "This lists the ERC-1155 contracts tracked for the selected account and network",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listTracked()
+	},
+}
+
+func init() {
+	MultiTokenCmd.AddCommand(trackCmd)
+	MultiTokenCmd.AddCommand(listCmd)
+	trackCmd.Flags().StringVarP(&track_contract, "contract", "c", "", "Address of the ERC-1155 contract")
+	trackCmd.MarkFlagRequired("contract")
+}
@@ -2,10 +2,11 @@ package network
 
 import (
 	"fmt"
-	"log"
+	"os"
 	"strconv"
+	"strings"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -13,66 +14,230 @@ import (
 
 var (
 	label            string
-	rpcURL           string
+	rpcURLs          []string
 	chainID          string
 	symbol           string
 	blockExplorerURL string
+	offline          bool
+	headers          []string
+	basicAuth        string
+	preset           string
+	chainlistFile    string
 )
 
-// Save the network to the database
-func saveNetwork(label, rpcURL, chainID, symbol, blockExplorerURL string) error {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
-	if err != nil {
-		utils.WriteToBucket(db, "networks", label, []byte(fmt.Sprintf(`{"rpcURL": "%s", "chainID": "%s", "symbol": "%s", "blockExplorerURL": "%s"}`, rpcURL, chainID, symbol, blockExplorerURL)))
+// resolveChainID returns the chain id reported by the RPC endpoints, failing
+// if they disagree with each other or with the one given. Offline, the given
+// chain id is trusted.
+func resolveChainID(network wallet.Network, given string, offline bool) (int, error) {
+	// Endpoints are checked before they are contacted, credentials included
+	for _, rpcURL := range network.Endpoints() {
+		err := wallet.ValidateRpcUrl(rpcURL)
+		if err != nil {
+			return 0, err
+		}
 	}
-	return nil
-}
-
-var addCmd = &cobra.Command{
-	Use:   "add",
-	Short: "Add a new network to the wallet",
-	Long: `add custom networks to the wallet's configuration, enabling you 
-	to connect to any Ethereum-based network using its specific Remote Procedure Call (RPC) endpoint.`,
-	Run: func(cmd *cobra.Command, args []string) {
-
-		var err error
 
-		db, err := utils.OpenDB(viper.GetString("database_file_path"))
+	expected := 0
+	if given != "" {
+		id, err := strconv.Atoi(given)
 		if err != nil {
-			log.Fatalf("Failed to open database: %v", err)
+			return 0, fmt.Errorf("failed to convert chain ID to integer: %w", err)
 		}
-		defer db.Close()
+		expected = id
+	}
+	if offline {
+		if given == "" {
+			return 0, fmt.Errorf("--chain-id is required with --offline")
+		}
+		return expected, nil
+	}
 
-		chainID, err := strconv.Atoi(chainID)
+	for _, rpcURL := range network.Endpoints() {
+		reported, err := wallet.GetChainID(rpcURL, network)
 		if err != nil {
-			log.Fatalf("Failed to convert chain ID to integer: %v", err)
+			return 0, fmt.Errorf("failed to verify chain ID of %s (use --offline to skip): %w", wallet.RedactUrl(rpcURL), err)
+		}
+		if given == "" {
+			output.Info("Using chain ID %d reported by %s", reported, wallet.RedactUrl(rpcURL))
+			given, expected = strconv.Itoa(reported), reported
+		}
+		if reported != expected {
+			return 0, fmt.Errorf("%w: expected chain ID %d but %s reports %d", wallet.ErrChainIDMismatch, expected, wallet.RedactUrl(rpcURL), reported)
 		}
+	}
+	return expected, nil
+}
 
-		network := wallet.Network{
-			Label:   label,
-			RpcUrl:  rpcURL,
-			ChainId: chainID,
-			Symbol:  symbol,
+// parseCredentials parses "Name: env:VAR" headers and a "user:env:VAR" basic
+// auth flag. The values must be secret references.
+func parseCredentials(headerFlags []string, basicAuthFlag string) (map[string]string, *wallet.BasicAuth, error) {
+	var headers map[string]string
+	for _, header := range headerFlags {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid header %q, expected \"Name: env:VAR\"", header)
 		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
 
-		err = wallet.AddNetwork(db, network)
+	if basicAuthFlag == "" {
+		return headers, nil, nil
+	}
+	username, password, ok := strings.Cut(basicAuthFlag, ":")
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid basic auth %q, expected \"user:env:VAR\"", basicAuthFlag)
+	}
+	return headers, &wallet.BasicAuth{Username: username, Password: password}, nil
+}
+
+// templateNetwork returns the network given by --preset or --chainlist, or
+// an empty network when neither is used.
+func templateNetwork() (wallet.Network, error) {
+	if preset != "" {
+		network, ok := wallet.Preset(preset)
+		if !ok {
+			return wallet.Network{}, fmt.Errorf("%w: no preset named %s, see network presets", wallet.ErrNetworkNotFound, preset)
+		}
+		return network, nil
+	}
+
+	if chainlistFile != "" {
+		id := 0
+		if chainID != "" {
+			var err error
+			id, err = strconv.Atoi(chainID)
+			if err != nil {
+				return wallet.Network{}, fmt.Errorf("failed to convert chain ID to integer: %w", err)
+			}
+		}
+
+		file, err := os.Open(chainlistFile)
+		if err != nil {
+			return wallet.Network{}, fmt.Errorf("failed to open chainlist: %w", err)
+		}
+		defer file.Close()
 
+		networks, err := wallet.ParseChainlist(file, id)
 		if err != nil {
-			log.Fatalf("Failed to save network: %v", err)
+			return wallet.Network{}, err
+		}
+		if len(networks) > 1 {
+			return wallet.Network{}, fmt.Errorf("%s has %d chains, choose one with --chain-id", chainlistFile, len(networks))
 		}
+		return networks[0], nil
+	}
+
+	return wallet.Network{}, nil
+}
+
+func addNetwork() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	network, err := templateNetwork()
+	if err != nil {
+		return err
+	}
+
+	// Flags override the preset or chainlist values
+	if label != "" {
+		network.Label = label
+	}
+	if len(rpcURLs) > 0 {
+		network.RpcUrl, network.RpcUrls = rpcURLs[0], rpcURLs[1:]
+	}
+	if symbol != "" {
+		network.Symbol = symbol
+	}
+	if blockExplorerURL != "" {
+		network.BlockExplorerUrl = blockExplorerURL
+	}
+	if network.Label == "" || network.RpcUrl == "" || network.Symbol == "" {
+		return fmt.Errorf("--label, --rpc-url and --symbol are required without --preset or --chainlist")
+	}
+
+	network.Headers, network.BasicAuth, err = parseCredentials(headers, basicAuth)
+	if err != nil {
+		return err
+	}
+
+	given := chainID
+	if given == "" && network.ChainId != 0 {
+		given = strconv.Itoa(network.ChainId)
+	}
+
+	network.ChainId, err = resolveChainID(network, given, offline)
+	if err != nil {
+		return err
+	}
+
+	err = wallet.AddNetwork(store, network)
+	if err != nil {
+		return fmt.Errorf("failed to save network: %w", err)
+	}
+	network.Selected = true
+
+	return output.Print(newNetworkOutput(network), func() {
 		fmt.Println("Network added successfully")
+	})
+}
+
+var addCmd = &cobra.Command{
+	Use:   "add",
+	Short: "Add a new network to the wallet",
+	Long: `add custom networks to the wallet's configuration, enabling you 
+	to connect to any Ethereum-based network using its specific Remote Procedure Call (RPC) endpoint.
+
+--rpc-url can be repeated to add fallback endpoints, in order of priority.
+When an endpoint cannot be reached the next one is used.
+
+Credentials are never stored: --header and --basic-auth take references to
+an environment variable (env:NAME) or a file (file:PATH), read whenever the
+network is used.
+
+  network add -l mainnet -s ETH -r https://rpc.example.org \
+    --header "X-Api-Key: env:RPC_API_KEY" --basic-auth "me:file:/run/secrets/rpc"
+
+Providers that take the key in the URL get a ${env:NAME} or ${file:PATH}
+placeholder in its place, URLs holding a key or a password are rejected:
+
+  network add -l mainnet -s ETH -r 'https://mainnet.infura.io/v3/${env:INFURA_KEY}'
+
+The chain ID is read from the endpoint with eth_chainId. When --chain-id is
+given it must match; with --offline the endpoint is not contacted and
+--chain-id is required.
+
+Well known networks can be added from a preset, see network presets:
+
+  network add --preset sepolia
+
+or from a chainlist-style JSON file, such as those of chainlist.org or
+ethereum-lists/chains, choosing the chain with --chain-id when the file
+holds several. Other flags override the values of the preset or file.
+
+  network add --chainlist chains.json --chain-id 10 --label optimism`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return addNetwork()
 	},
 }
 
 func init() {
 	NetworkCmd.AddCommand(addCmd)
 	addCmd.Flags().StringVarP(&label, "label", "l", "", "Label for the network to be identified with")
-	addCmd.Flags().StringVarP(&rpcURL, "rpc-url", "r", "", "RPC URL for the network to be added")
-	addCmd.Flags().StringVarP(&chainID, "chain-id", "c", "", "Chain ID for the network to be added")
+	addCmd.Flags().StringSliceVarP(&rpcURLs, "rpc-url", "r", nil, "RPC endpoint for the network to be added: an http(s) or ws(s) URL or an IPC socket path, repeat for fallbacks")
+	addCmd.Flags().StringVarP(&chainID, "chain-id", "c", "", "Chain ID for the network to be added (read from the RPC endpoint when omitted)")
+	addCmd.Flags().StringArrayVar(&headers, "header", nil, "Header sent to the RPC endpoints as \"Name: env:VAR\" or \"Name: file:PATH\", can be repeated")
+	addCmd.Flags().StringVar(&basicAuth, "basic-auth", "", "Basic auth credentials for the RPC endpoints as \"user:env:VAR\" or \"user:file:PATH\"")
+	addCmd.Flags().BoolVar(&offline, "offline", false, "Do not contact the RPC endpoint to verify the chain ID")
 	addCmd.Flags().StringVarP(&symbol, "symbol", "s", "", "Symbol for the network to be added")
-	addCmd.Flags().StringVarP(&blockExplorerURL, "block-explorer-url", "b", "", "Block Explorer URL for the network to be added")
-	addCmd.MarkFlagRequired("label")
-	addCmd.MarkFlagRequired("rpc-url")
-	addCmd.MarkFlagRequired("chain-id")
-	addCmd.MarkFlagRequired("symbol")
+	addCmd.Flags().StringVarP(&blockExplorerURL, "block-explorer-url", "b", "", "Block Explorer URL for the network to be added, used to print links to transactions and addresses")
+	addCmd.Flags().StringVarP(&preset, "preset", "p", "", "Add a well known network, see network presets")
+	addCmd.Flags().StringVar(&chainlistFile, "chainlist", "", "Add a network from a chainlist-style JSON file")
+	addCmd.MarkFlagsMutuallyExclusive("preset", "chainlist")
 }
@@ -0,0 +1,77 @@
+package network
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	export_out    string
+	export_labels []string
+)
+
+func exportNetworks() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	var networks []wallet.Network
+	if len(export_labels) > 0 {
+		for _, label := range export_labels {
+			network, err := wallet.GetNetwork(store, label)
+			if err != nil {
+				return fmt.Errorf("failed to get network: %w", err)
+			}
+			networks = append(networks, network)
+		}
+	} else {
+		networks, err = wallet.ListNetworks(store)
+		if err != nil {
+			return fmt.Errorf("failed to list networks: %w", err)
+		}
+	}
+
+	data, err := wallet.ExportNetworks(networks)
+	if err != nil {
+		return fmt.Errorf("failed to export networks: %w", err)
+	}
+	err = os.WriteFile(export_out, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write networks: %w", err)
+	}
+
+	labels := make([]string, 0, len(networks))
+	for _, network := range networks {
+		labels = append(labels, network.Label)
+	}
+	return output.Print(map[string]interface{}{"file": export_out, "networks": labels}, func() {
+		fmt.Printf("%d networks exported to %s\n", len(networks), export_out)
+	})
+}
+
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Write network definitions to a YAML file that can be shared",
+	Long: `Writes the networks of the wallet, or those given with --label, to a YAML file that
+can be committed to a repository and loaded with network import. Headers, basic auth and
+URL placeholders are written as their env:/file: references, so the file holds no secret.
+Networks whose RPC URLs hold a password or an API key are refused; update them with a
+placeholder first.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return exportNetworks()
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVar(&export_out, "out", "", "File to write the networks to")
+	exportCmd.Flags().StringSliceVarP(&export_labels, "label", "l", nil, "Networks to export, all by default")
+	exportCmd.MarkFlagRequired("out")
+}
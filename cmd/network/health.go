@@ -0,0 +1,95 @@
+package network
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	label_health string
+)
+
+// endpointOutput is the json/yaml structure of the health of an endpoint.
+// LatencyMs is the time eth_blockNumber took and Lag the number of blocks
+// behind the most advanced endpoint.
+type endpointOutput struct {
+	Priority    int    `json:"priority" yaml:"priority"`
+	Url         string `json:"url" yaml:"url"`
+	Healthy     bool   `json:"healthy" yaml:"healthy"`
+	ChainId     int    `json:"chainId,omitempty" yaml:"chainId,omitempty"`
+	BlockNumber uint64 `json:"blockNumber,omitempty" yaml:"blockNumber,omitempty"`
+	LatencyMs   int64  `json:"latencyMs,omitempty" yaml:"latencyMs,omitempty"`
+	Lag         uint64 `json:"lag" yaml:"lag"`
+	Error       string `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+func showHealth() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	var network wallet.Network
+	if label_health != "" {
+		network, err = wallet.GetNetwork(store, label_health)
+	} else {
+		network, err = selection.GetNetwork(store)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	results := wallet.CheckEndpoints(network)
+	endpoints := make([]endpointOutput, 0, len(results))
+	for _, result := range results {
+		endpoints = append(endpoints, endpointOutput{
+			Priority:    result.Priority,
+			Url:         wallet.RedactUrl(result.Url),
+			Healthy:     result.Healthy,
+			ChainId:     result.ChainId,
+			BlockNumber: result.BlockNumber,
+			LatencyMs:   result.Latency.Milliseconds(),
+			Lag:         result.Lag,
+			Error:       result.Error,
+		})
+	}
+
+	return output.Print(endpoints, func() {
+		fmt.Printf("Endpoints of %s (chain ID %d):\n", network.Label, network.ChainId)
+		fmt.Println("")
+		for _, result := range results {
+			fmt.Printf("%d. %s\n", result.Priority, wallet.RedactUrl(result.Url))
+			if !result.Healthy {
+				fmt.Println("Status: ", "unhealthy:", result.Error)
+			} else {
+				fmt.Println("Status: ", "healthy")
+				fmt.Println("Block: ", result.BlockNumber, fmt.Sprintf("(%d behind)", result.Lag))
+				fmt.Println("Latency: ", result.Latency.Round(time.Millisecond))
+			}
+			fmt.Println("------------------------------------------------------------------------------------------")
+		}
+	})
+}
+
+var healthCmd = &cobra.Command{
+	Use:   "health",
+	Short: "Check the RPC endpoints of a network",
+	Long: `Probes every RPC endpoint of a network, the selected one by default, and
+shows whether it answers with the network's chain ID, its latest block, how
+far it lags behind the other endpoints and the latency of eth_blockNumber.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showHealth()
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(healthCmd)
+	healthCmd.Flags().StringVarP(&label_health, "label", "l", "", "Label of the network to check, the selected network by default")
+}
@@ -0,0 +1,120 @@
+package network
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	import_file        string
+	import_dry_run     bool
+	import_on_conflict string
+	import_no_verify   bool
+)
+
+// importOutput is the json/yaml structure of an imported network. Changes
+// lists the fields that differ from the network stored under Label.
+type importOutput struct {
+	Label     string   `json:"label" yaml:"label"`
+	FileLabel string   `json:"fileLabel" yaml:"fileLabel"`
+	Action    string   `json:"action" yaml:"action"`
+	Changes   []string `json:"changes,omitempty" yaml:"changes,omitempty"`
+}
+
+func importNetworks() error {
+	data, err := os.ReadFile(import_file)
+	if err != nil {
+		return fmt.Errorf("failed to read networks: %w", err)
+	}
+	networks, err := wallet.ParseNetworks(data)
+	if err != nil {
+		return err
+	}
+	// The endpoints are asked before the database is opened, as it is locked
+	// while open
+	if !import_no_verify {
+		err = verifyChainIDs(networks)
+		if err != nil {
+			return err
+		}
+	}
+
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	results, err := wallet.ImportNetworks(store, networks, wallet.ImportOptions{OnConflict: import_on_conflict, DryRun: import_dry_run})
+	if err != nil {
+		return fmt.Errorf("failed to import networks: %w", err)
+	}
+
+	outputs := make([]importOutput, 0, len(results))
+	for _, result := range results {
+		outputs = append(outputs, importOutput{Label: result.Label, FileLabel: result.BackupLabel, Action: result.Action, Changes: result.Changes})
+	}
+	return output.Print(outputs, func() {
+		if import_dry_run {
+			fmt.Println("Dry run, nothing was changed:")
+		}
+		for _, result := range outputs {
+			if result.Label != result.FileLabel {
+				fmt.Printf("Network %s: %s as %s\n", result.FileLabel, result.Action, result.Label)
+			} else {
+				fmt.Printf("Network %s: %s\n", result.FileLabel, result.Action)
+			}
+			for _, change := range result.Changes {
+				fmt.Printf("  %s\n", change)
+			}
+		}
+	})
+}
+
+// verifyChainIDs checks that every endpoint of the networks reports the
+// chain ID of its network, as network add does, so that a wrong chain ID in
+// a shared file is never trusted for signing.
+func verifyChainIDs(networks []wallet.Network) error {
+	for _, network := range networks {
+		for _, rpcURL := range network.Endpoints() {
+			reported, err := wallet.GetChainID(rpcURL, network)
+			if err != nil {
+				return fmt.Errorf("failed to verify chain ID of network %s at %s (use --no-verify to skip): %w", network.Label, wallet.RedactUrl(rpcURL), err)
+			}
+			if reported != network.ChainId {
+				return fmt.Errorf("%w: network %s has chain ID %d but %s reports %d", wallet.ErrChainIDMismatch, network.Label, network.ChainId, wallet.RedactUrl(rpcURL), reported)
+			}
+		}
+	}
+	return nil
+}
+
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Add the networks of a file written by network export",
+	Long: `Adds the networks of a file written by network export. Networks identical to a stored
+one are left unchanged; for a label that exists with other settings --on-conflict decides:
+fail (the default, nothing is imported), skip, overwrite or rename (with an -imported
+suffix). --dry-run shows what would change, with the differing fields, without writing.
+The selected network stays selected.
+
+Every endpoint is asked for its chain ID, which must match the file, unless --no-verify is
+given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return importNetworks()
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(importCmd)
+	importCmd.Flags().StringVarP(&import_file, "file", "f", "", "File written by network export")
+	importCmd.Flags().BoolVar(&import_dry_run, "dry-run", false, "Show what would change without writing")
+	importCmd.Flags().StringVar(&import_on_conflict, "on-conflict", wallet.ConflictFail, "What to do with a label that exists with other settings: fail, skip, overwrite or rename")
+	importCmd.Flags().BoolVar(&import_no_verify, "no-verify", false, "Do not contact the RPC endpoints to verify the chain IDs")
+	importCmd.MarkFlagRequired("file")
+}
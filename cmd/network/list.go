@@ -2,41 +2,56 @@ package network
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-func getNetworks() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+var (
+	list_archived bool
+)
+
+func getNetworks() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	networks, err := wallet.ListNetworks(db) // Call listNetworks function
+	listNetworks, title := wallet.ListNetworks, "Networks:"
+	if list_archived {
+		listNetworks, title = wallet.ListArchivedNetworks, "Archived networks:"
+	}
+	networks, err := listNetworks(store)
 	if err != nil {
-		log.Fatalf("Failed to list networks: %v", err)
+		return fmt.Errorf("failed to list networks: %w", err)
 	}
-	fmt.Println("Networks:")
-	fmt.Println("")
+
+	result := make([]networkOutput, 0, len(networks))
 	for _, network := range networks {
-		printNetwork(network)
+		result = append(result, newNetworkOutput(network))
 	}
+	return output.Print(result, func() {
+		fmt.Println(title)
+		fmt.Println("")
+		for _, network := range networks {
+			printNetwork(network)
+		}
+	})
 }
 
 var listCmd = &cobra.Command{
 	Use:   "list",
 	Short: "This list all the networks available for the wallet",
 	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		getNetworks()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return getNetworks()
 	},
 }
 
 func init() {
 	NetworkCmd.AddCommand(listCmd)
+	listCmd.Flags().BoolVar(&list_archived, "archived", false, "List the networks archived with rm --archive")
 }
@@ -7,15 +7,77 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// networkOutput is the json/yaml structure of a network.
+type networkOutput struct {
+	Label   string   `json:"label" yaml:"label"`
+	ChainId int      `json:"chainId" yaml:"chainId"`
+	RpcUrl  string   `json:"rpcUrl" yaml:"rpcUrl"`
+	RpcUrls []string `json:"rpcUrls,omitempty" yaml:"rpcUrls,omitempty"`
+	// Headers and BasicAuth show the secret references, never the secrets
+	Headers   map[string]string `json:"headers,omitempty" yaml:"headers,omitempty"`
+	BasicAuth string            `json:"basicAuth,omitempty" yaml:"basicAuth,omitempty"`
+
+	BlockExplorerUrl string `json:"blockExplorerUrl,omitempty" yaml:"blockExplorerUrl,omitempty"`
+	Symbol           string `json:"symbol" yaml:"symbol"`
+	Selected         bool   `json:"selected" yaml:"selected"`
+}
+
+func newNetworkOutput(network wallet.Network) networkOutput {
+	result := networkOutput{
+		Label:    network.Label,
+		ChainId:  network.ChainId,
+		RpcUrl:   wallet.RedactUrl(network.RpcUrl),
+		Symbol:   network.Symbol,
+		Selected: network.Selected,
+
+		BlockExplorerUrl: network.BlockExplorerUrl,
+	}
+	for _, rpcUrl := range network.RpcUrls {
+		result.RpcUrls = append(result.RpcUrls, wallet.RedactUrl(rpcUrl))
+	}
+	if len(network.Headers) > 0 {
+		result.Headers = make(map[string]string)
+		for name, value := range network.Headers {
+			result.Headers[name] = redactSecret(value)
+		}
+	}
+	if network.BasicAuth != nil {
+		result.BasicAuth = network.BasicAuth.Username + ":" + redactSecret(network.BasicAuth.Password)
+	}
+	return result
+}
+
+// redactSecret shows secret references, which only name where the secret is
+// kept, and hides any other value.
+func redactSecret(value string) string {
+	if wallet.IsSecretReference(value) {
+		return value
+	}
+	return "xxxxx"
+}
+
 func printNetwork(network wallet.Network) {
+	result := newNetworkOutput(network)
 	if network.Selected {
 		fmt.Println("Label:", network.Label, "(Selected)")
 	} else {
 		fmt.Println("Label:", network.Label)
 	}
 	fmt.Println("Chain ID: ", network.ChainId)
-	fmt.Println("RPC URL: ", network.RpcUrl)
+	fmt.Println("RPC URL: ", result.RpcUrl)
+	for _, rpcUrl := range result.RpcUrls {
+		fmt.Println("Fallback RPC URL: ", rpcUrl)
+	}
+	for name, value := range result.Headers {
+		fmt.Println("Header: ", name+":", value)
+	}
+	if result.BasicAuth != "" {
+		fmt.Println("Basic Auth: ", result.BasicAuth)
+	}
 	fmt.Println("Symbol: ", network.Symbol)
+	if network.BlockExplorerUrl != "" {
+		fmt.Println("Block Explorer URL: ", network.BlockExplorerUrl)
+	}
 	fmt.Println("------------------------------------------------------------------------------------------")
 }
 
@@ -0,0 +1,38 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+)
+
+func listPresets() error {
+	presets := wallet.Presets()
+
+	result := make([]networkOutput, 0, len(presets))
+	for _, network := range presets {
+		result = append(result, newNetworkOutput(network))
+	}
+	return output.Print(result, func() {
+		fmt.Println("Presets:")
+		fmt.Println("")
+		for _, network := range presets {
+			printNetwork(network)
+		}
+	})
+}
+
+var presetsCmd = &cobra.Command{
+	Use:   "presets",
+	Short: "List the well known networks that can be added with network add --preset",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listPresets()
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(presetsCmd)
+}
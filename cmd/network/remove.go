@@ -2,49 +2,72 @@ package network
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var (
-	label_remove string
+	label_remove   string
+	remove_force   bool
+	remove_archive bool
 )
 
-func removeNetwork() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+func removeNetwork() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	network, err := wallet.GetNetwork(db, label_remove)
+	network, err := wallet.GetNetwork(store, label_remove)
 	if err != nil {
-		log.Fatalf("Failed to get network: %v", err)
+		return fmt.Errorf("failed to get network: %w", err)
 	}
 
-	err = wallet.DeleteNetwork(db, network.Label)
+	if network.Selected && !remove_force {
+		return fmt.Errorf("network %s is selected, use --force to remove it", network.Label)
+	}
+
+	if remove_archive {
+		err = wallet.ArchiveNetwork(store, network.Label)
+		if err != nil {
+			return fmt.Errorf("failed to archive network: %w", err)
+		}
+		return output.Print(map[string]string{"archived": network.Label}, func() {
+			fmt.Printf("Network %s archived\n", network.Label)
+		})
+	}
+
+	err = wallet.DeleteNetwork(store, network.Label)
 	if err != nil {
-		log.Fatalf("Failed to delete network: %v", err)
+		return fmt.Errorf("failed to delete network: %w", err)
 	}
 
-	fmt.Printf("Network %s deleted\n", network.Label)
+	return output.Print(map[string]string{"removed": network.Label}, func() {
+		fmt.Printf("Network %s deleted\n", network.Label)
+	})
 }
 
 var removeCmd = &cobra.Command{
 	Use:   "rm",
 	Short: "This command removes a network with the given label",
-	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		removeNetwork()
+	Long: `Remove a network from the wallet.
+
+The selected network is only removed with --force, and another network is
+selected in its place. With --archive the network is moved to the archive
+instead: see network list --archived and network unarchive.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return removeNetwork()
 	},
 }
 
 func init() {
 	NetworkCmd.AddCommand(removeCmd)
 	removeCmd.Flags().StringVarP(&label_remove, "label", "l", "", "Label for the network to be deleted")
+	removeCmd.Flags().BoolVarP(&remove_force, "force", "f", false, "Remove the network even if it is selected")
+	removeCmd.Flags().BoolVar(&remove_archive, "archive", false, "Move the network to the archive instead of deleting it")
 	removeCmd.MarkFlagRequired("label")
 }
@@ -2,9 +2,8 @@ package network
 
 import (
 	"fmt"
-	"log"
 
-	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/cmd/output"
 	"github.com/EliasManj/go-wallet/wallet"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -14,32 +13,34 @@ var (
 	label_select string
 )
 
-func selectNetwork() {
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+func selectNetwork() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	network, err := wallet.GetNetwork(db, label_select)
+	network, err := wallet.GetNetwork(store, label_select)
 	if err != nil {
-		log.Fatalf("Failed to get network: %v", err)
+		return fmt.Errorf("failed to get network: %w", err)
 	}
 
-	err = wallet.SelectNetwork(db, network.Label)
+	err = wallet.SelectNetwork(store, network.Label)
 	if err != nil {
-		log.Fatalf("Failed to select network: %v", err)
+		return fmt.Errorf("failed to select network: %w", err)
 	}
 
-	fmt.Printf("Network %s selected\n", network.Label)
+	return output.Print(map[string]string{"selected": network.Label}, func() {
+		fmt.Printf("Network %s selected\n", network.Label)
+	})
 }
 
 var selectCmd = &cobra.Command{
 	Use:   "select",
 	Short: "This selects the specified network to be the active network",
 	Long:  ``,
-	Run: func(cmd *cobra.Command, args []string) {
-		selectNetwork()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return selectNetwork()
 	},
 }
 
@@ -0,0 +1,46 @@
+package network
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	label_unarchive string
+)
+
+func unarchiveNetwork() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	err = wallet.UnarchiveNetwork(store, label_unarchive)
+	if err != nil {
+		return fmt.Errorf("failed to unarchive network: %w", err)
+	}
+
+	return output.Print(map[string]string{"unarchived": label_unarchive}, func() {
+		fmt.Printf("Network %s restored from the archive\n", label_unarchive)
+	})
+}
+
+var unarchiveCmd = &cobra.Command{
+	Use:   "unarchive",
+	Short: "Restore an archived network",
+	Long:  `Move a network archived with rm --archive back into the wallet. It is not selected.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return unarchiveNetwork()
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(unarchiveCmd)
+	unarchiveCmd.Flags().StringVarP(&label_unarchive, "label", "l", "", "Label of the archived network")
+	unarchiveCmd.MarkFlagRequired("label")
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	update_label          string
+	update_rename         string
+	update_rpc_urls       []string
+	update_chain_id       string
+	update_symbol         string
+	update_block_explorer string
+	update_headers        []string
+	update_basic_auth     string
+	update_offline        bool
+)
+
+func updateNetwork(cmd *cobra.Command) error {
+	flags := cmd.Flags()
+	changed := false
+	for _, name := range []string{"rename", "rpc-url", "chain-id", "symbol", "block-explorer-url", "header", "basic-auth"} {
+		changed = changed || flags.Changed(name)
+	}
+	if !changed {
+		return fmt.Errorf("nothing to update, pass at least one of the network's fields")
+	}
+
+	// The database is locked while it is open, so it is closed while the
+	// endpoints are asked for the chain ID
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	original, err := wallet.GetNetwork(store, update_label)
+	store.Close()
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+	network := original
+
+	if flags.Changed("rename") {
+		network.Label = update_rename
+	}
+	if flags.Changed("rpc-url") {
+		if len(update_rpc_urls) == 0 {
+			return fmt.Errorf("--rpc-url must not be empty")
+		}
+		network.RpcUrl, network.RpcUrls = update_rpc_urls[0], update_rpc_urls[1:]
+	}
+	if flags.Changed("symbol") {
+		network.Symbol = update_symbol
+	}
+	if flags.Changed("block-explorer-url") {
+		network.BlockExplorerUrl = update_block_explorer
+	}
+	if flags.Changed("header") || flags.Changed("basic-auth") {
+		headers, basicAuth, err := parseCredentials(update_headers, update_basic_auth)
+		if err != nil {
+			return err
+		}
+		if flags.Changed("header") {
+			network.Headers = headers
+		}
+		if flags.Changed("basic-auth") {
+			network.BasicAuth = basicAuth
+		}
+	}
+
+	// The endpoints must serve the chain of the network whenever either changes
+	if flags.Changed("chain-id") || flags.Changed("rpc-url") || flags.Changed("header") || flags.Changed("basic-auth") {
+		chainID := update_chain_id
+		if chainID == "" {
+			chainID = strconv.Itoa(network.ChainId)
+		}
+		network.ChainId, err = resolveChainID(network, chainID, update_offline)
+		if err != nil {
+			return err
+		}
+	}
+
+	store, err = wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	err = wallet.UpdateNetwork(store, update_label, func(stored *wallet.Network) error {
+		stored.Selected = original.Selected
+		if !reflect.DeepEqual(*stored, original) {
+			return fmt.Errorf("network %s changed while it was being updated, try again", update_label)
+		}
+		*stored = network
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update network: %w", err)
+	}
+
+	return output.Print(newNetworkOutput(network), func() {
+		fmt.Printf("Network %s updated\n", network.Label)
+		fmt.Println("")
+		printNetwork(network)
+	})
+}
+
+var updateCmd = &cobra.Command{
+	Use:   "update",
+	Short: "Change the settings of a network",
+	Long: `Changes the RPC endpoints, chain ID, symbol, block explorer or credentials of a network,
+or renames it with --rename. Only the flags given are changed; --rpc-url and --header replace
+all the endpoints and headers. The network stays selected if it was, and the ERC-1155
+contracts tracked on it follow a rename.
+
+The chain ID is checked against the endpoints whenever they or the chain ID change, unless
+--offline is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return updateNetwork(cmd)
+	},
+}
+
+func init() {
+	NetworkCmd.AddCommand(updateCmd)
+	updateCmd.Flags().StringVarP(&update_label, "label", "l", "", "Label of the network to update")
+	updateCmd.Flags().StringVar(&update_rename, "rename", "", "New label for the network")
+	updateCmd.Flags().StringSliceVarP(&update_rpc_urls, "rpc-url", "r", nil, "RPC endpoints replacing the current ones, repeat for fallbacks")
+	updateCmd.Flags().StringVarP(&update_chain_id, "chain-id", "c", "", "Chain ID of the network")
+	updateCmd.Flags().StringVarP(&update_symbol, "symbol", "s", "", "Symbol of the network")
+	updateCmd.Flags().StringVarP(&update_block_explorer, "block-explorer-url", "b", "", "Block Explorer URL of the network, empty to remove it")
+	updateCmd.Flags().StringArrayVar(&update_headers, "header", nil, "Header replacing the current ones as \"Name: env:VAR\" or \"Name: file:PATH\", can be repeated")
+	updateCmd.Flags().StringVar(&update_basic_auth, "basic-auth", "", "Basic auth credentials as \"user:env:VAR\" or \"user:file:PATH\", empty to remove them")
+	updateCmd.Flags().BoolVar(&update_offline, "offline", false, "Do not contact the RPC endpoints to verify the chain ID")
+	updateCmd.MarkFlagRequired("label")
+}
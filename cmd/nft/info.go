@@ -0,0 +1,82 @@
+package nft
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	info_contract string
+	info_id       string
+)
+
+// infoOutput is the json/yaml structure of an ERC-721 token.
+type infoOutput struct {
+	Contract string `json:"contract" yaml:"contract"`
+	TokenId  string `json:"tokenId" yaml:"tokenId"`
+	Owner    string `json:"owner" yaml:"owner"`
+	TokenURI string `json:"tokenURI" yaml:"tokenURI"`
+}
+
+func showInfo() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if !common.IsHexAddress(info_contract) {
+		return fmt.Errorf("invalid contract address: %s", info_contract)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	tokenId, err := parseTokenId(info_id)
+	if err != nil {
+		return fmt.Errorf("failed to parse token id: %w", err)
+	}
+
+	nft, err := wallet.GetNFT(info_contract, tokenId, network)
+	if err != nil {
+		return fmt.Errorf("failed to get token: %w", err)
+	}
+
+	result := infoOutput{
+		Contract: nft.Contract,
+		TokenId:  nft.TokenId.String(),
+		Owner:    nft.Owner,
+		TokenURI: nft.TokenURI,
+	}
+	return output.Print(result, func() {
+		fmt.Println("Contract: ", nft.Contract)
+		fmt.Println("Token ID: ", nft.TokenId)
+		fmt.Println("Owner: ", nft.Owner)
+		fmt.Println("Token URI: ", nft.TokenURI)
+	})
+}
+
+var infoCmd = &cobra.Command{
+	Use:   "info",
+	Short: "This displays the owner and token URI of an ERC-721 token",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return showInfo()
+	},
+}
+
+func init() {
+	NftCmd.AddCommand(infoCmd)
+	infoCmd.Flags().StringVarP(&info_contract, "contract", "c", "", "Address of the ERC-721 contract")
+	infoCmd.MarkFlagRequired("contract")
+	infoCmd.Flags().StringVarP(&info_id, "id", "i", "", "ID of the token")
+	infoCmd.MarkFlagRequired("id")
+}
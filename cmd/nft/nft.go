@@ -0,0 +1,25 @@
+package nft
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/spf13/cobra"
+)
+
+var NftCmd = &cobra.Command{
+	Use:   "nft",
+	Short: "Nft is a palette that contains ERC-721 based commands",
+	Long:  `Query and transfer ERC-721 tokens held by the selected account on the selected network`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func parseTokenId(id string) (*big.Int, error) {
+	tokenId, ok := new(big.Int).SetString(id, 0)
+	if !ok || tokenId.Sign() < 0 {
+		return nil, fmt.Errorf("invalid token id %q", id)
+	}
+	return tokenId, nil
+}
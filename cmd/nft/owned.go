@@ -0,0 +1,80 @@
+package nft
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	owned_contract   string
+	owned_from_block uint64
+)
+
+// ownedOutput is the json/yaml structure of the tokens owned by an account.
+type ownedOutput struct {
+	Contract string   `json:"contract" yaml:"contract"`
+	Owner    string   `json:"owner" yaml:"owner"`
+	TokenIds []string `json:"tokenIds" yaml:"tokenIds"`
+}
+
+func listOwned() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if !common.IsHexAddress(owned_contract) {
+		return fmt.Errorf("invalid contract address: %s", owned_contract)
+	}
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	ids, err := wallet.ListOwnedNFTs(owned_contract, account.Publicy, owned_from_block, network)
+	if err != nil {
+		return fmt.Errorf("failed to list tokens: %w", err)
+	}
+
+	result := ownedOutput{Contract: owned_contract, Owner: account.Publicy, TokenIds: []string{}}
+	for _, id := range ids {
+		result.TokenIds = append(result.TokenIds, id.String())
+	}
+	return output.Print(result, func() {
+		fmt.Printf("Tokens of %s owned by %s:\n", owned_contract, account.Label)
+		fmt.Println("")
+		for _, id := range ids {
+			fmt.Println("Token ID:", id)
+		}
+	})
+}
+
+var ownedCmd = &cobra.Command{
+	Use:   "owned",
+	Short: "This lists the tokens of an ERC-721 contract owned by the selected account",
+	Long: `Lists the ERC-721 tokens owned by the selected account. Contracts implementing the
+enumeration extension are queried directly, otherwise the Transfer logs are scanned.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listOwned()
+	},
+}
+
+func init() {
+	NftCmd.AddCommand(ownedCmd)
+	ownedCmd.Flags().StringVarP(&owned_contract, "contract", "c", "", "Address of the ERC-721 contract")
+	ownedCmd.MarkFlagRequired("contract")
+	ownedCmd.Flags().Uint64Var(&owned_from_block, "from-block", 0, "First block to scan for Transfer logs on non-enumerable contracts")
+}
@@ -0,0 +1,100 @@
+package nft
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	send_contract string
+	send_id       string
+	send_to       string
+)
+
+// sendOutput is the json/yaml structure of an ERC-721 transfer. The
+// transaction is sent to the contract, Recipient receives the token.
+type sendOutput struct {
+	output.Transaction `yaml:",inline"`
+	TokenId            string `json:"tokenId" yaml:"tokenId"`
+	Recipient          string `json:"recipient" yaml:"recipient"`
+}
+
+func sendNFT() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if !common.IsHexAddress(send_contract) {
+		return fmt.Errorf("invalid contract address: %s", send_contract)
+	}
+	if !common.IsHexAddress(send_to) {
+		return fmt.Errorf("invalid recipient address: %s", send_to)
+	}
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	tokenId, err := parseTokenId(send_id)
+	if err != nil {
+		return fmt.Errorf("failed to parse token id: %w", err)
+	}
+
+	output.Info("Sending token %s from account %s on network %s", tokenId, account.Label, network.Label)
+
+	tx, err := wallet.SendNFT(account.Privatey, send_contract, send_to, tokenId, network)
+	if err != nil {
+		return fmt.Errorf("failed to send token: %w", err)
+	}
+
+	result := sendOutput{
+		Transaction: output.NewTransaction(tx),
+		TokenId:     tokenId.String(),
+		Recipient:   send_to,
+	}
+	return output.Print(result, func() {
+		fmt.Printf("Transaction hash: %s\n", tx.Hash)
+		fmt.Printf("Contract: %s\n", tx.To)
+		fmt.Printf("Token ID: %s\n", tokenId)
+		fmt.Printf("From: %s\n", tx.From)
+		fmt.Printf("To: %s\n", send_to)
+		fmt.Printf("Gas: %s\n", tx.GasUsed)
+		fmt.Printf("Gas price: %s\n", tx.GasPrice)
+		output.PrintLink(tx.Network.TransactionUrl(tx.Hash))
+	})
+}
+
+var sendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send an ERC-721 token from the selected account using safeTransferFrom",
+	Long: `Send an ERC-721 token from the selected account using safeTransferFrom. The transfer is
+simulated first, so recipient contracts that do not implement onERC721Received are reported
+before the transaction is signed.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendNFT()
+	},
+}
+
+func init() {
+	NftCmd.AddCommand(sendCmd)
+	sendCmd.Flags().StringVarP(&send_contract, "contract", "c", "", "Address of the ERC-721 contract")
+	sendCmd.MarkFlagRequired("contract")
+	sendCmd.Flags().StringVarP(&send_id, "id", "i", "", "ID of the token to send")
+	sendCmd.MarkFlagRequired("id")
+	sendCmd.Flags().StringVarP(&send_to, "to", "t", "", "Address to send the token to")
+	sendCmd.MarkFlagRequired("to")
+}
@@ -0,0 +1,123 @@
+// Package output renders the results of every command in the format chosen
+// with the global --output flag.
+//
+// In the table format commands print human readable text. In the json and
+// yaml formats a single document is written to stdout:
+//
+//	{"ok": true, "profile": "default", "data": ...}
+//	{"ok": false, "profile": "default", "error": {"message": "...", "code": 3}}
+//
+// In the table format the profile is printed as a header instead. Progress
+// messages are written to stderr so that stdout only ever holds
+// that document.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	Table = "table"
+	JSON  = "json"
+	YAML  = "yaml"
+)
+
+// Format is bound to the global --output flag.
+var Format = Table
+
+// Profile is the name of the wallet profile a command runs with, empty when
+// an explicit config file is used instead.
+var Profile string
+
+var Stdout io.Writer = os.Stdout
+var Stderr io.Writer = os.Stderr
+
+// Error describes a failure. Code is the exit code of the process.
+type Error struct {
+	Message string `json:"message" yaml:"message"`
+	Code    int    `json:"code" yaml:"code"`
+}
+
+type envelope struct {
+	OK      bool        `json:"ok" yaml:"ok"`
+	Profile string      `json:"profile,omitempty" yaml:"profile,omitempty"`
+	Data    interface{} `json:"data,omitempty" yaml:"data,omitempty"`
+	Error   *Error      `json:"error,omitempty" yaml:"error,omitempty"`
+}
+
+// Validate checks the value of the --output flag.
+func Validate() error {
+	switch Format {
+	case Table, JSON, YAML:
+		return nil
+	}
+	return fmt.Errorf("unknown output format %q, expected table, json or yaml", Format)
+}
+
+// IsTable reports whether human readable output was requested.
+func IsTable() bool {
+	return Format != JSON && Format != YAML
+}
+
+// Print writes the result of a command. In the table format table is called
+// to print it, otherwise data is encoded.
+func Print(data interface{}, table func()) error {
+	if IsTable() {
+		table()
+		return nil
+	}
+	return encode(envelope{OK: true, Profile: Profile, Data: data})
+}
+
+// Header prints the profile a command runs with in the table format.
+func Header() {
+	if IsTable() && Profile != "" {
+		fmt.Fprintf(Stdout, "Profile: %s\n", Profile)
+	}
+}
+
+// Info prints a progress message, to stdout in the table format and to
+// stderr otherwise.
+func Info(format string, args ...interface{}) {
+	if IsTable() {
+		fmt.Fprintf(Stdout, format+"\n", args...)
+		return
+	}
+	fmt.Fprintf(Stderr, format+"\n", args...)
+}
+
+// PrintError writes the error a command failed with and the exit code the
+// process is about to exit with.
+func PrintError(err error, code int) {
+	if IsTable() {
+		fmt.Fprintln(Stderr, "Error:", err)
+		return
+	}
+	if encodeErr := encode(envelope{OK: false, Profile: Profile, Error: &Error{Message: err.Error(), Code: code}}); encodeErr != nil {
+		fmt.Fprintln(Stderr, "Error:", err)
+	}
+}
+
+func encode(v envelope) error {
+	switch Format {
+	case YAML:
+		encoder := yaml.NewEncoder(Stdout)
+		encoder.SetIndent(2)
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("yaml encode: %v", err)
+		}
+		return encoder.Close()
+	default:
+		encoder := json.NewEncoder(Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(v); err != nil {
+			return fmt.Errorf("json encode: %v", err)
+		}
+		return nil
+	}
+}
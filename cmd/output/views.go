@@ -0,0 +1,59 @@
+package output
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/EliasManj/go-wallet/wallet"
+)
+
+// Transaction is the json/yaml structure of a sent transaction. Amounts are
+// decimal strings of wei, or of the token's smallest unit for ERC-20
+// transfers, which also set Token.
+type Transaction struct {
+	Hash     string `json:"hash" yaml:"hash"`
+	Network  string `json:"network" yaml:"network"`
+	ChainId  int    `json:"chainId" yaml:"chainId"`
+	From     string `json:"from" yaml:"from"`
+	To       string `json:"to" yaml:"to"`
+	Amount   string `json:"amount" yaml:"amount"`
+	GasUsed  string `json:"gasUsed" yaml:"gasUsed"`
+	GasPrice string `json:"gasPrice" yaml:"gasPrice"`
+	Fee      string `json:"fee" yaml:"fee"`
+	Token    string `json:"token,omitempty" yaml:"token,omitempty"`
+	// Explorer links to the transaction when the network has a block explorer
+	Explorer string `json:"explorer,omitempty" yaml:"explorer,omitempty"`
+}
+
+func NewTransaction(tx wallet.Transaction) Transaction {
+	return Transaction{
+		Hash:     tx.Hash,
+		Network:  tx.Network.Label,
+		ChainId:  tx.Network.ChainId,
+		From:     tx.From,
+		To:       tx.To,
+		Amount:   bigString(tx.Amount),
+		GasUsed:  bigString(tx.GasUsed),
+		GasPrice: bigString(tx.GasPrice),
+		Fee:      bigString(new(big.Int).Mul(orZero(tx.GasUsed), orZero(tx.GasPrice))),
+		Explorer: tx.Network.TransactionUrl(tx.Hash),
+	}
+}
+
+// PrintLink prints a block explorer link in table output, if there is one.
+func PrintLink(url string) {
+	if url != "" {
+		fmt.Printf("Explorer: %s\n", url)
+	}
+}
+
+func bigString(n *big.Int) string {
+	return orZero(n).String()
+}
+
+func orZero(n *big.Int) *big.Int {
+	if n == nil {
+		return new(big.Int)
+	}
+	return n
+}
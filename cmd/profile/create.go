@@ -0,0 +1,99 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	create_name     string
+	create_database string
+	create_use      bool
+)
+
+// profileOutput is the json/yaml structure of a profile.
+type profileOutput struct {
+	Name     string `json:"name" yaml:"name"`
+	Config   string `json:"config" yaml:"config"`
+	Database string `json:"database" yaml:"database"`
+	Active   bool   `json:"active" yaml:"active"`
+}
+
+func createProfile() error {
+	if err := validate(create_name); err != nil {
+		return err
+	}
+
+	exists, err := Exists(create_name)
+	if err != nil {
+		return fmt.Errorf("failed to look up profile: %w", err)
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", ErrExists, create_name)
+	}
+
+	database := create_database
+	if database == "" {
+		database, err = DatabaseFile(create_name)
+		if err != nil {
+			return fmt.Errorf("failed to find the data directory: %w", err)
+		}
+	}
+	database, err = filepath.Abs(database)
+	if err != nil {
+		return fmt.Errorf("failed to resolve database path: %w", err)
+	}
+
+	path, err := ConfigFile(create_name)
+	if err != nil {
+		return fmt.Errorf("failed to find the config directory: %w", err)
+	}
+	config, err := yaml.Marshal(map[string]string{"database_file_path": database})
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	err = os.WriteFile(path, config, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+
+	if create_use {
+		err = use(create_name)
+		if err != nil {
+			return err
+		}
+	}
+
+	result := profileOutput{Name: create_name, Config: path, Database: database, Active: create_use}
+	return output.Print(result, func() {
+		fmt.Printf("Profile %s created\n", create_name)
+		fmt.Println("Config:", path)
+		fmt.Println("Database:", database)
+	})
+}
+
+var createCmd = &cobra.Command{
+	Use:   "create",
+	Short: "This creates a profile with its own config file and database",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return createProfile()
+	},
+}
+
+func init() {
+	ProfileCmd.AddCommand(createCmd)
+	createCmd.Flags().StringVarP(&create_name, "name", "n", "", "Name of the profile")
+	createCmd.MarkFlagRequired("name")
+	createCmd.Flags().StringVar(&create_database, "database", "", "Database file of the profile, defaults to one under $XDG_DATA_HOME/eth-wallet/profiles")
+	createCmd.Flags().BoolVar(&create_use, "use", false, "Make the new profile the active one")
+}
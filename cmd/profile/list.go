@@ -0,0 +1,61 @@
+package profile
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+func listProfiles() error {
+	names, err := List()
+	if err != nil {
+		return err
+	}
+
+	active, err := Active()
+	if err != nil {
+		return err
+	}
+
+	profiles := make([]profileOutput, 0, len(names))
+	for _, name := range names {
+		config, err := ConfigFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to find the config directory: %w", err)
+		}
+		database, err := Database(name)
+		if err != nil {
+			return err
+		}
+		profiles = append(profiles, profileOutput{Name: name, Config: config, Database: database, Active: name == active})
+	}
+
+	return output.Print(profiles, func() {
+		fmt.Println("Profiles:")
+		fmt.Println("")
+		for _, profile := range profiles {
+			if profile.Active {
+				fmt.Println("Name:", profile.Name, "(Active)")
+			} else {
+				fmt.Println("Name:", profile.Name)
+			}
+			fmt.Println("Config:", profile.Config)
+			fmt.Println("Database:", profile.Database)
+			fmt.Println("------------------------------------------------------------------------------------------")
+		}
+	})
+}
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "This lists the profiles and marks the active one",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return listProfiles()
+	},
+}
+
+func init() {
+	ProfileCmd.AddCommand(listCmd)
+}
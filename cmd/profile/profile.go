@@ -0,0 +1,219 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Default is the profile used when none is chosen. Its config file is the
+// wallet's main config.yaml, so wallets set up before profiles keep working.
+const Default = "default"
+
+var (
+	ErrNotFound = errors.New("profile not found")
+	ErrExists   = errors.New("profile already exists")
+)
+
+var validName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+var ProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Profile is a palette that contains commands to manage wallet profiles",
+	Long: `Profiles keep separate wallets, such as treasury, testing and personal, each with its own
+config file and database. The active profile is used unless --profile or WALLET_PROFILE chooses
+another one.`,
+	// Profiles are managed without loading one, so that a broken profile can
+	// still be replaced or removed
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return output.Validate()
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// ConfigHome returns the directory holding the configuration of the wallet,
+// $XDG_CONFIG_HOME/eth-wallet or ~/.config/eth-wallet.
+func ConfigHome() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "eth-wallet"), nil
+}
+
+// DataHome returns the directory holding the databases of the wallet,
+// $XDG_DATA_HOME/eth-wallet or ~/.local/share/eth-wallet.
+func DataHome() (string, error) {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dir, "eth-wallet"), nil
+}
+
+// ConfigFile returns the path of the config file of a profile.
+func ConfigFile(name string) (string, error) {
+	dir, err := ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	if name == Default {
+		return filepath.Join(dir, "config.yaml"), nil
+	}
+	return filepath.Join(dir, "profiles", name+".yaml"), nil
+}
+
+// DatabaseFile returns the default path of the database of a profile.
+func DatabaseFile(name string) (string, error) {
+	dir, err := DataHome()
+	if err != nil {
+		return "", err
+	}
+	if name == Default {
+		return filepath.Join(dir, "wallet.db"), nil
+	}
+	return filepath.Join(dir, "profiles", name, "wallet.db"), nil
+}
+
+func activeFile() (string, error) {
+	dir, err := ConfigHome()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "profile"), nil
+}
+
+func validate(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q, use letters, digits, '-' and '_'", name)
+	}
+	return nil
+}
+
+// Exists reports whether a profile has been created. The default profile
+// always exists.
+func Exists(name string) (bool, error) {
+	if name == Default {
+		return true, nil
+	}
+	path, err := ConfigFile(name)
+	if err != nil {
+		return false, err
+	}
+	_, err = os.Stat(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// Active returns the profile chosen with profile use, or the default profile.
+func Active() (string, error) {
+	path, err := activeFile()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read active profile: %w", err)
+	}
+	name := strings.TrimSpace(string(data))
+	if name == "" {
+		return Default, nil
+	}
+	return name, nil
+}
+
+// Resolve returns the profile to run with: the given name if not empty,
+// then WALLET_PROFILE, then the active profile. It fails if the profile does
+// not exist.
+func Resolve(name string) (string, error) {
+	if name == "" {
+		name = os.Getenv("WALLET_PROFILE")
+	}
+	if name == "" {
+		active, err := Active()
+		if err != nil {
+			return "", err
+		}
+		name = active
+	}
+
+	exists, err := Exists(name)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	return name, nil
+}
+
+// List returns the names of every profile, sorted, including the default one.
+func List() ([]string, error) {
+	dir, err := ConfigHome()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "profiles"))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to list profiles: %w", err)
+	}
+
+	names := []string{Default}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".yaml")
+		if ok && !entry.IsDir() && validName.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[1:])
+	return names, nil
+}
+
+// Database returns the database file a profile uses, as set in its config
+// file or the default one. A relative path is relative to the config file.
+func Database(name string) (string, error) {
+	path, err := ConfigFile(name)
+	if err != nil {
+		return "", err
+	}
+
+	config := viper.New()
+	config.SetConfigFile(path)
+	err = config.ReadInConfig()
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return "", fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	database := config.GetString("database_file_path")
+	if database == "" {
+		return DatabaseFile(name)
+	}
+	if !filepath.IsAbs(database) {
+		database = filepath.Join(filepath.Dir(path), database)
+	}
+	return database, nil
+}
@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newConfigHome points the wallet at empty config and data directories.
+func newConfigHome(t *testing.T) string {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("WALLET_PROFILE", "")
+	dir, err := ConfigHome()
+	require.NoError(t, err)
+	return dir
+}
+
+func writeProfile(t *testing.T, name string, config string) string {
+	path, err := ConfigFile(name)
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+	require.NoError(t, os.WriteFile(path, []byte(config), 0600))
+	return path
+}
+
+func TestResolveOrder(t *testing.T) {
+	dir := newConfigHome(t)
+	for _, name := range []string{"flag", "env", "active"} {
+		writeProfile(t, name, "")
+	}
+
+	name, err := Resolve("")
+	require.NoError(t, err)
+	require.Equal(t, Default, name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "profile"), []byte("active\n"), 0600))
+	name, err = Resolve("")
+	require.NoError(t, err)
+	require.Equal(t, "active", name)
+
+	t.Setenv("WALLET_PROFILE", "env")
+	name, err = Resolve("")
+	require.NoError(t, err)
+	require.Equal(t, "env", name)
+
+	name, err = Resolve("flag")
+	require.NoError(t, err)
+	require.Equal(t, "flag", name)
+
+	_, err = Resolve("missing")
+	require.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestDatabase(t *testing.T) {
+	newConfigHome(t)
+
+	database, err := Database(Default)
+	require.NoError(t, err)
+	expected, err := DatabaseFile(Default)
+	require.NoError(t, err)
+	require.Equal(t, expected, database)
+
+	path := writeProfile(t, "relative", "database_file_path: db/wallet.db\n")
+	database, err = Database("relative")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(filepath.Dir(path), "db", "wallet.db"), database)
+
+	absolute := filepath.Join(t.TempDir(), "wallet.db")
+	writeProfile(t, "absolute", "database_file_path: "+absolute+"\n")
+	database, err = Database("absolute")
+	require.NoError(t, err)
+	require.Equal(t, absolute, database)
+}
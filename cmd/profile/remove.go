@@ -0,0 +1,81 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	remove_name  string
+	remove_purge bool
+)
+
+func removeProfile() error {
+	if remove_name == Default {
+		return fmt.Errorf("the %s profile cannot be removed", Default)
+	}
+
+	exists, err := Exists(remove_name)
+	if err != nil {
+		return fmt.Errorf("failed to look up profile: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNotFound, remove_name)
+	}
+
+	active, err := Active()
+	if err != nil {
+		return err
+	}
+	if remove_name == active {
+		return fmt.Errorf("profile %s is active, use another profile before removing it", remove_name)
+	}
+
+	database, err := Database(remove_name)
+	if err != nil {
+		return err
+	}
+	path, err := ConfigFile(remove_name)
+	if err != nil {
+		return fmt.Errorf("failed to find the config directory: %w", err)
+	}
+
+	err = os.Remove(path)
+	if err != nil {
+		return fmt.Errorf("failed to remove config file: %w", err)
+	}
+	// The database holds private keys, so it is only deleted when asked to
+	if remove_purge {
+		err = os.Remove(database)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove database: %w", err)
+		}
+	}
+
+	result := map[string]interface{}{"removed": remove_name, "database": database, "purged": remove_purge}
+	return output.Print(result, func() {
+		fmt.Printf("Profile %s removed\n", remove_name)
+		if !remove_purge {
+			fmt.Println("Its database was kept at", database)
+		}
+	})
+}
+
+var removeCmd = &cobra.Command{
+	Use:   "rm",
+	Short: "This removes a profile",
+	Long:  `Removes the config file of a profile. Its database is kept unless --purge is given.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return removeProfile()
+	},
+}
+
+func init() {
+	ProfileCmd.AddCommand(removeCmd)
+	removeCmd.Flags().StringVarP(&remove_name, "name", "n", "", "Name of the profile")
+	removeCmd.MarkFlagRequired("name")
+	removeCmd.Flags().BoolVar(&remove_purge, "purge", false, "Also delete the database of the profile")
+}
@@ -0,0 +1,65 @@
+package profile
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/spf13/cobra"
+)
+
+var (
+	use_name string
+)
+
+// use makes a profile the active one.
+func use(name string) error {
+	path, err := activeFile()
+	if err != nil {
+		return fmt.Errorf("failed to find the config directory: %w", err)
+	}
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+	err = os.WriteFile(path, []byte(name+"\n"), 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write active profile: %w", err)
+	}
+	return nil
+}
+
+func useProfile() error {
+	exists, err := Exists(use_name)
+	if err != nil {
+		return fmt.Errorf("failed to look up profile: %w", err)
+	}
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrNotFound, use_name)
+	}
+
+	err = use(use_name)
+	if err != nil {
+		return err
+	}
+
+	return output.Print(map[string]string{"active": use_name}, func() {
+		fmt.Printf("Profile %s is now active\n", use_name)
+	})
+}
+
+var useCmd = &cobra.Command{
+	Use:   "use",
+	Short: "This makes the specified profile the active one",
+	Long:  ``,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return useProfile()
+	},
+}
+
+func init() {
+	ProfileCmd.AddCommand(useCmd)
+	useCmd.Flags().StringVarP(&use_name, "name", "n", "", "Name of the profile")
+	useCmd.MarkFlagRequired("name")
+}
@@ -4,30 +4,66 @@ Copyright © 2022 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/EliasManj/go-wallet/cmd/account"
+	"github.com/EliasManj/go-wallet/cmd/backup"
+	"github.com/EliasManj/go-wallet/cmd/db"
+	"github.com/EliasManj/go-wallet/cmd/multitoken"
 	"github.com/EliasManj/go-wallet/cmd/network"
+	"github.com/EliasManj/go-wallet/cmd/nft"
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/profile"
+	"github.com/EliasManj/go-wallet/cmd/selection"
 	"github.com/EliasManj/go-wallet/cmd/send"
+	"github.com/EliasManj/go-wallet/cmd/token"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
 var cfgFile string
+var profileName string
 var debugMode bool
 
 var rootCmd = &cobra.Command{
 	Use:   "wallet",
 	Short: "ETH Wallet CLI",
-	Long:  `Command-line interface (CLI) application written in Go, designed for managing Ethereum assets.`,
+	Long: `Command-line interface (CLI) application written in Go, designed for managing Ethereum assets.
+
+Every command accepts --output json or --output yaml to emit a single document
+{"ok": true, "data": ...} on success or {"ok": false, "error": {"message": ..., "code": ...}}
+on failure, for scripts. Failures always exit with a non-zero status.
+
+--account (a label or an address) and --network (a label or a chain ID) run a
+single command with another account or network than the selected ones,
+without changing the selection.
+
+` + exitCodesHelp,
+	SilenceErrors: true,
+	SilenceUsage:  true,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := output.Validate(); err != nil {
+			return usageError{err}
+		}
+		if err := initConfig(); err != nil {
+			return err
+		}
+		output.Header()
+		return nil
+	},
 }
 
 func Execute() {
 	err := rootCmd.Execute()
 	if err != nil {
-		os.Exit(1)
+		code := exitCode(err)
+		output.PrintError(err, code)
+		os.Exit(code)
 	}
 }
 
@@ -36,9 +72,18 @@ func setDefaults() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "Config file (default $XDG_CONFIG_HOME/eth-wallet/config.yaml, or $WALLET_CONFIG), whose database defaults to wallet.db next to it")
+	rootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Profile to use instead of the active one (or $WALLET_PROFILE)")
+	rootCmd.MarkFlagsMutuallyExclusive("config", "profile")
 	rootCmd.PersistentFlags().BoolVarP(&debugMode, "debug", "d", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringVarP(&output.Format, "output", "o", output.Table, "Output format: table, json or yaml")
+	rootCmd.PersistentFlags().StringVar(&selection.Account, "account", "", "Account to use instead of the selected one, by label or address")
+	rootCmd.PersistentFlags().StringVar(&selection.Network, "network", "", "Network to use instead of the selected one, by label or chain ID")
+
+	rootCmd.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return usageError{err}
+	})
 
-	cobra.OnInitialize(initConfig)
 	setDefaults()
 
 	if debugMode {
@@ -51,70 +96,106 @@ func init() {
 	rootCmd.AddCommand(account.AccountCmd)
 	rootCmd.AddCommand(send.SendEthCmd)
 	rootCmd.AddCommand(send.SendWeiCmd)
+	rootCmd.AddCommand(send.SweepCmd)
+	rootCmd.AddCommand(send.SendCmd)
+	rootCmd.AddCommand(nft.NftCmd)
+	rootCmd.AddCommand(multitoken.MultiTokenCmd)
+	rootCmd.AddCommand(token.TokenCmd)
+	rootCmd.AddCommand(profile.ProfileCmd)
+	rootCmd.AddCommand(db.DbCmd)
+	rootCmd.AddCommand(backup.BackupCmd)
+	rootCmd.AddCommand(backup.RestoreCmd)
 
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
 
-func initConfig() {
+func initConfig() error {
 	if debugMode {
-		fmt.Println("Initializing configuration")
+		fmt.Fprintln(os.Stderr, "Initializing configuration")
 	}
 
-	if cfgFile != "" {
-		if debugMode {
-			fmt.Println("Using config file from flag:", cfgFile)
+	// Every setting can be overridden with a WALLET_ variable, such as
+	// WALLET_DATABASE_FILE_PATH for database_file_path
+	viper.SetEnvPrefix("WALLET")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_", "-", "_"))
+	viper.AutomaticEnv()
+
+	explicit := cfgFile != ""
+	if !explicit {
+		cfgFile = os.Getenv("WALLET_CONFIG")
+		explicit = cfgFile != ""
+	}
+
+	// Without an explicit config file the profile decides which config file
+	// and database are used
+	name := profile.Default
+	if !explicit {
+		var err error
+		name, err = profile.Resolve(profileName)
+		if err != nil {
+			return err
 		}
-		viper.SetConfigFile(cfgFile)
-	} else {
-		// Add the current directory as a search path
-		currentDir, err := os.Getwd()
+		output.Profile = name
+
+		cfgFile, err = profile.ConfigFile(name)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to get current directory: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to find the config directory: %w", err)
 		}
-		if debugMode {
-			fmt.Println("Current directory:", currentDir)
+	}
+
+	// An explicit config file keeps its default database next to it, apart
+	// from the databases of the profiles
+	database := filepath.Join(filepath.Dir(cfgFile), "wallet.db")
+	if !explicit {
+		var err error
+		database, err = profile.DatabaseFile(name)
+		if err != nil {
+			return fmt.Errorf("failed to find the data directory: %w", err)
 		}
-		viper.AddConfigPath(currentDir)
-		viper.SetConfigType("yaml")
-		viper.SetConfigName("config")
 	}
+	viper.SetDefault("database_file_path", database)
 
-	if err := viper.ReadInConfig(); err == nil {
-		if debugMode {
-			fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	viper.SetConfigFile(cfgFile)
+	err := viper.ReadInConfig()
+	if err != nil {
+		// Only a config file that was asked for has to exist
+		if explicit || !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to read config file %s: %w", cfgFile, err)
 		}
-	} else {
 		if debugMode {
-			fmt.Fprintf(os.Stderr, "No config file found: %v\n", err)
+			fmt.Fprintln(os.Stderr, "No config file found at", cfgFile)
 		}
+	} else if debugMode {
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
 	}
 
-	viper.AutomaticEnv()
-	checkAndCreateDBPath()
+	// A relative database path is relative to the config file that sets it,
+	// not to the directory the wallet runs in
+	dbPath := viper.GetString("database_file_path")
+	if dbPath != "" && !filepath.IsAbs(dbPath) && viper.InConfig("database_file_path") && os.Getenv("WALLET_DATABASE_FILE_PATH") == "" {
+		viper.Set("database_file_path", filepath.Join(filepath.Dir(cfgFile), dbPath))
+	}
+
+	return checkAndCreateDBPath()
 }
 
-func checkAndCreateDBPath() {
-	if debugMode {
-		fmt.Println("CREATING DB PATH")
-	}
+func checkAndCreateDBPath() error {
 	dbPath := viper.GetString("database_file_path")
 	if dbPath == "" {
-		fmt.Fprintln(os.Stderr, "Database file path not set.")
-		return
+		return fmt.Errorf("database file path not set")
 	}
 	dir := filepath.Dir(dbPath)
 
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		if debugMode {
-			fmt.Println("Creating directories for database file path:", dir)
+			fmt.Fprintln(os.Stderr, "Creating directories for database file path:", dir)
 		}
-		err = os.MkdirAll(dir, os.ModePerm)
+		err = os.MkdirAll(dir, 0700)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error creating directories: %v\n", err)
-			os.Exit(1)
+			return fmt.Errorf("failed to create the database directory: %w", err)
 		}
 	}
+	return nil
 }
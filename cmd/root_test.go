@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+// loadConfig runs initConfig with a config file given as with --config.
+func loadConfig(t *testing.T, config string) string {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	t.Setenv("WALLET_CONFIG", "")
+	t.Setenv("WALLET_DATABASE_FILE_PATH", "")
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+
+	dir := t.TempDir()
+	cfgFile = filepath.Join(dir, "config.yaml")
+	t.Cleanup(func() { cfgFile = "" })
+	require.NoError(t, os.WriteFile(cfgFile, []byte(config), 0600))
+
+	require.NoError(t, initConfig())
+	return dir
+}
+
+func TestInitConfigRelativeDatabase(t *testing.T) {
+	dir := loadConfig(t, "database_file_path: db/wallet.db\n")
+	require.Equal(t, filepath.Join(dir, "db", "wallet.db"), viper.GetString("database_file_path"))
+}
+
+func TestInitConfigExplicitConfigDatabase(t *testing.T) {
+	dir := loadConfig(t, "")
+	require.Equal(t, filepath.Join(dir, "wallet.db"), viper.GetString("database_file_path"))
+}
@@ -0,0 +1,31 @@
+// Package selection resolves the account and network a command runs with:
+// the ones given with the global --account and --network flags, or else the
+// ones selected in the wallet. The flags apply to a single invocation and
+// never change the stored selection.
+package selection
+
+import (
+	"github.com/EliasManj/go-wallet/wallet"
+)
+
+// Account is bound to the global --account flag, a label or an address.
+var Account string
+
+// Network is bound to the global --network flag, a label or a chain id.
+var Network string
+
+// GetAccount returns the account given with --account, or the selected one.
+func GetAccount(store wallet.Store) (wallet.Account, error) {
+	if Account == "" {
+		return wallet.GetSelectedAccount(store)
+	}
+	return wallet.FindAccount(store, Account)
+}
+
+// GetNetwork returns the network given with --network, or the selected one.
+func GetNetwork(store wallet.Store) (wallet.Network, error) {
+	if Network == "" {
+		return wallet.GetSelectedNetwork(store)
+	}
+	return wallet.FindNetwork(store, Network)
+}
@@ -0,0 +1,354 @@
+package send
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	batch_file    string
+	batch_results string
+	batch_yes     bool
+)
+
+func resultsPath() string {
+	if batch_results != "" {
+		return batch_results
+	}
+	return strings.TrimSuffix(batch_file, ".csv") + ".results.csv"
+}
+
+func confirm(question string) bool {
+	fmt.Fprintf(output.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+func describeAsset(asset string, symbol string) string {
+	if asset == "" {
+		return symbol
+	}
+	return asset
+}
+
+func describeAmount(amount *big.Int, asset string, symbol string) string {
+	if asset == "" {
+		formatted, _ := utils.FormatAmount(amount, "ether", -1)
+		return fmt.Sprintf("%s %s", formatted, symbol)
+	}
+	return fmt.Sprintf("%s of %s", amount, asset)
+}
+
+// checkFunds verifies that the account holds enough of every asset for the
+// payments that are still to be sent.
+func checkFunds(account wallet.Account, network wallet.Network, totals map[string]*big.Int, nativeFees *big.Int) error {
+	for asset, total := range totals {
+		var balance *big.Int
+		var err error
+		required := new(big.Int).Set(total)
+		if asset == "" {
+			balance, err = wallet.GetBalance(account.Publicy, network)
+			required.Add(required, nativeFees)
+		} else {
+			balance, err = wallet.GetTokenBalance(asset, account.Publicy, network)
+		}
+		if err != nil {
+			return err
+		}
+		if balance.Cmp(required) < 0 {
+			return fmt.Errorf("%w of %s: need %s, have %s", wallet.ErrInsufficientFunds, describeAsset(asset, network.Symbol), required, balance)
+		}
+	}
+	return nil
+}
+
+// paymentOutput is the json/yaml structure of a row of a batch.
+type paymentOutput struct {
+	Row     int     `json:"row" yaml:"row"`
+	Address string  `json:"address" yaml:"address"`
+	Amount  string  `json:"amount" yaml:"amount"`
+	Asset   string  `json:"asset" yaml:"asset"`
+	Nonce   *uint64 `json:"nonce,omitempty" yaml:"nonce,omitempty"`
+	Hash    string  `json:"hash,omitempty" yaml:"hash,omitempty"`
+	Status  string  `json:"status" yaml:"status"`
+	Error   string  `json:"error,omitempty" yaml:"error,omitempty"`
+
+	Explorer string `json:"explorer,omitempty" yaml:"explorer,omitempty"`
+}
+
+// batchOutput is the json/yaml structure of a batch. Asset is empty for the
+// native currency and amounts are decimal strings of the smallest unit.
+type batchOutput struct {
+	File     string          `json:"file" yaml:"file"`
+	Results  string          `json:"results" yaml:"results"`
+	Payments []paymentOutput `json:"payments" yaml:"payments"`
+}
+
+func newBatchOutput(path string, results []wallet.PaymentResult, network wallet.Network) batchOutput {
+	payments := make([]paymentOutput, 0, len(results))
+	for _, result := range results {
+		status := result.Status
+		if status == "" {
+			status = "skipped"
+		}
+		payments = append(payments, paymentOutput{
+			Row:     result.Row,
+			Address: result.Address,
+			Amount:  result.Amount.String(),
+			Asset:   result.Asset,
+			Nonce:   result.Nonce,
+			Hash:    result.Hash,
+			Status:  status,
+			Error:   result.Error,
+
+			Explorer: network.TransactionUrl(result.Hash),
+		})
+	}
+	return batchOutput{File: batch_file, Results: path, Payments: payments}
+}
+
+func batchFunction() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	file, err := os.Open(batch_file)
+	if err != nil {
+		return fmt.Errorf("failed to open payments: %w", err)
+	}
+	payments, err := wallet.ParsePayments(file, network.Symbol)
+	file.Close()
+	if err != nil {
+		return fmt.Errorf("failed to parse payments: %w", err)
+	}
+
+	// Resume from the results of a previous run of the same file
+	path := resultsPath()
+	previous, err := wallet.ReadPaymentResults(path)
+	if err != nil {
+		return fmt.Errorf("failed to read results: %w", err)
+	}
+
+	results := make([]wallet.PaymentResult, len(payments))
+	var remaining []wallet.Payment
+	for i, payment := range payments {
+		result, ok := previous[payment.Row]
+		if ok && (result.Address != payment.Address || result.Asset != payment.Asset || result.Amount.Cmp(payment.Amount) != 0) {
+			return fmt.Errorf("results file %s does not match row %d of %s", path, payment.Row, batch_file)
+		}
+		result.Payment = payment
+		results[i] = result
+		if result.Status != wallet.PaymentConfirmed && result.Status != wallet.PaymentReverted {
+			remaining = append(remaining, payment)
+		}
+	}
+
+	if len(remaining) == 0 {
+		return output.Print(newBatchOutput(path, results, network), func() {
+			fmt.Printf("All %d payments of %s are already done, see %s\n", len(payments), batch_file, path)
+		})
+	}
+
+	sender, err := wallet.NewSender(account.Privatey, network)
+	if err != nil {
+		return fmt.Errorf("failed to connect: %w", err)
+	}
+	defer sender.Close()
+
+	gasPrice, err := sender.GasPrice()
+	if err != nil {
+		return fmt.Errorf("failed to get gas price: %w", err)
+	}
+
+	// Every payment, token transfers included, pays its fee in the native
+	// currency
+	totals := wallet.PaymentTotals(remaining)
+	nativeFees := new(big.Int)
+	for _, payment := range remaining {
+		gasLimit, err := sender.EstimateGas(payment)
+		if err != nil {
+			return fmt.Errorf("failed to estimate the fee of row %d: %w", payment.Row, err)
+		}
+		nativeFees.Add(nativeFees, new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice))
+	}
+
+	output.Info("Sending %d of %d payments from account %s on network %s", len(remaining), len(payments), account.Label, network.Label)
+	output.Info("Total outlay:")
+	for asset, total := range totals {
+		output.Info("  %s", describeAmount(total, asset, network.Symbol))
+	}
+	output.Info("Estimated fees: %s", describeAmount(nativeFees, "", network.Symbol))
+
+	err = checkFunds(account, network, totals, nativeFees)
+	if err != nil {
+		return fmt.Errorf("failed to validate balance: %w", err)
+	}
+
+	if !batch_yes && !confirm("Proceed?") {
+		return fmt.Errorf("aborted")
+	}
+
+	save := func() error {
+		if err := wallet.WritePaymentResults(path, results); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+		return nil
+	}
+
+	var nextNonce *uint64
+	for i := range results {
+		result := &results[i]
+		if result.Status == wallet.PaymentConfirmed || result.Status == wallet.PaymentReverted {
+			continue
+		}
+
+		if result.Hash != "" && result.Nonce != nil && (result.Status == wallet.PaymentPending || result.Status == wallet.PaymentFailed) {
+			// Signed by an earlier run, which may have reached the node even if
+			// the broadcast failed: wait for it if the node knows it, otherwise
+			// broadcast it again with the same nonce so it can never be paid twice
+			known, err := sender.Known(result.Hash)
+			if err != nil {
+				return fmt.Errorf("failed to look up row %d: %w", result.Row, err)
+			}
+			if !known {
+				confirmed, err := sender.ConfirmedNonce()
+				if err != nil {
+					return fmt.Errorf("failed to look up row %d: %w", result.Row, err)
+				}
+				if confirmed > *result.Nonce {
+					if err := failRow(result, network, save); err != nil {
+						return err
+					}
+					continue
+				}
+				if err := signAndBroadcast(sender, result, *result.Nonce, save); err != nil {
+					return fmt.Errorf("row %d: %w", result.Row, err)
+				}
+			}
+		} else {
+			if nextNonce == nil {
+				nonce, err := sender.PendingNonce()
+				if err != nil {
+					return fmt.Errorf("failed to get nonce: %w", err)
+				}
+				nextNonce = &nonce
+			}
+			if err := signAndBroadcast(sender, result, *nextNonce, save); err != nil {
+				return fmt.Errorf("row %d: %w", result.Row, err)
+			}
+			*nextNonce++
+		}
+
+		receipt, err := sender.WaitForReceipt(result.Hash, *result.Nonce)
+		if errors.Is(err, wallet.ErrNonceUsed) {
+			if err := failRow(result, network, save); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("row %d: %w", result.Row, err)
+		}
+		if receipt.Status == types.ReceiptStatusSuccessful {
+			result.Status = wallet.PaymentConfirmed
+		} else {
+			result.Status = wallet.PaymentReverted
+		}
+		if err := save(); err != nil {
+			return err
+		}
+
+		output.Info("Row %d: %s to %s %s %s", result.Row, describeAmount(result.Amount, result.Asset, network.Symbol), result.Address, result.Status, result.Hash)
+		if url := network.TransactionUrl(result.Hash); url != "" {
+			output.Info("  %s", url)
+		}
+	}
+
+	return output.Print(newBatchOutput(path, results, network), func() {
+		fmt.Printf("Results written to %s\n", path)
+	})
+}
+
+// failRow fails a row whose nonce was used by another transaction, so that
+// its own transaction can never be mined. The row keeps its hash and nonce
+// and is never signed again: the other transaction may be the payment itself
+// if the node no longer knows its hash, which the account history tells.
+func failRow(result *wallet.PaymentResult, network wallet.Network, save func() error) error {
+	result.Status = wallet.PaymentFailed
+	result.Error = fmt.Sprintf("nonce %d was used by another transaction, check the account history", *result.Nonce)
+	if err := save(); err != nil {
+		return err
+	}
+	output.Info("Row %d: %s to %s failed: %s", result.Row, describeAmount(result.Amount, result.Asset, network.Symbol), result.Address, result.Error)
+	return nil
+}
+
+// signAndBroadcast records the signed transaction before broadcasting it, so
+// a crash in between leaves enough in the results file to resume safely.
+func signAndBroadcast(sender *wallet.Sender, result *wallet.PaymentResult, nonce uint64, save func() error) error {
+	tx, err := sender.SignPayment(result.Payment, nonce)
+	if err != nil {
+		return err
+	}
+
+	result.Nonce = &nonce
+	result.Hash = tx.Hash().Hex()
+	result.Status = wallet.PaymentPending
+	result.Error = ""
+	if err := save(); err != nil {
+		return err
+	}
+
+	err = sender.Broadcast(tx)
+	if err != nil {
+		result.Status = wallet.PaymentFailed
+		result.Error = err.Error()
+		if saveErr := save(); saveErr != nil {
+			return saveErr
+		}
+		return err
+	}
+	return nil
+}
+
+var batchCmd = &cobra.Command{
+	Use:   "batch",
+	Short: "Send payments to many addresses from a CSV file",
+	Long: `Send payments listed in a CSV file with address, amount and asset columns from the selected
+account. Native amounts are in ETH, token amounts in the token's smallest unit; the asset is
+ETH (or the network symbol) or an ERC-20 token address.
+
+Every row is validated and the total outlay is checked against the balances before anything is
+sent. Payments are sent one after the other with locally assigned nonces and the outcome of each
+row is written to a results CSV. Running the same file again resumes after the last confirmed
+row. A row signed before, even one whose broadcast failed, is only ever sent again with its
+nonce, so it cannot be paid twice; it fails if that nonce was used by another transaction.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return batchFunction()
+	},
+}
@@ -2,11 +2,13 @@ package send
 
 import (
 	"fmt"
-	"log"
 	"math/big"
 
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
 	"github.com/EliasManj/go-wallet/utils"
 	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -14,101 +16,132 @@ import (
 var (
 	amount_send string
 	to_send     string
+	max_send    bool
 )
 
-func sendWeiFunction() {
+func sendWeiFunction() error {
+	if !common.IsHexAddress(to_send) {
+		return fmt.Errorf("invalid destination address: %s", to_send)
+	}
 
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+		return fmt.Errorf("failed to open database: %w", err)
 	}
-	defer db.Close()
+	defer store.Close()
 
-	account, err := wallet.GetSelectedAccount(db)
+	account, err := selection.GetAccount(store)
 	if err != nil {
-		fmt.Printf("Failed to get account: %v\n", err)
-		return
+		return fmt.Errorf("failed to get account: %w", err)
 	}
 
-	network, err := wallet.GetSelectedNetwork(db)
+	network, err := selection.GetNetwork(store)
 	if err != nil {
-		fmt.Printf("Failed to get network: %v\n", err)
-		return
+		return fmt.Errorf("failed to get network: %w", err)
 	}
 
-	amount := new(big.Int)
-	amount, ok := amount.SetString(amount_send, 10)
-	if !ok {
-		fmt.Printf("Failed to convert amount to big.Int\n")
-		return
+	amount, err := utils.ParseAmount(amount_send, "wei")
+	if err != nil {
+		return fmt.Errorf("failed to parse amount: %w", err)
 	}
 
-	fmt.Printf("Sending from account %s on network %s\n", account.Label, network.Label)
+	output.Info("Sending from account %s on network %s", account.Label, network.Label)
 
 	tx, err := wallet.SendWei(account.Privatey, to_send, amount, network)
 	if err != nil {
-		fmt.Printf("Failed to send ETH: %v\n", err)
-		return
+		return fmt.Errorf("failed to send ETH: %w", err)
 	}
 
-	printTx(tx)
-
+	return output.Print(output.NewTransaction(tx), func() {
+		printTx(tx)
+	})
 }
 
-func sendEthFunction() {
-
-	db, err := utils.OpenDB(viper.GetString("database_file_path"))
-	if err != nil {
-		log.Fatalf("Failed to open database: %v", err)
+func sendEthFunction() error {
+	if !common.IsHexAddress(to_send) {
+		return fmt.Errorf("invalid destination address: %s", to_send)
 	}
-	defer db.Close()
 
-	account, err := wallet.GetSelectedAccount(db)
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
 	if err != nil {
-		fmt.Printf("Failed to get account: %v\n", err)
-		return
+		return fmt.Errorf("failed to open database: %w", err)
 	}
+	defer store.Close()
 
-	network, err := wallet.GetSelectedNetwork(db)
+	account, err := selection.GetAccount(store)
 	if err != nil {
-		fmt.Printf("Failed to get network: %v\n", err)
-		return
+		return fmt.Errorf("failed to get account: %w", err)
 	}
 
-	amount := new(big.Float)
-	amount, ok := amount.SetString(amount_send)
-	if !ok {
-		fmt.Printf("Failed to convert amount to big.Int\n")
-		return
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
 	}
 
-	fmt.Printf("Sending from account %s on network %s\n", account.Label, network.Label)
+	output.Info("Sending from account %s on network %s", account.Label, network.Label)
 
-	tx, err := wallet.SendETH(account.Privatey, to_send, amount, network)
+	var tx wallet.Transaction
+	if max_send {
+		tx, err = wallet.SendMaxETH(account.Privatey, to_send, network)
+	} else {
+		tx, err = wallet.SendETH(account.Privatey, to_send, amount_send, network)
+	}
 	if err != nil {
-		fmt.Printf("Failed to send ETH: %v\n", err)
-		return
+		return fmt.Errorf("failed to send ETH: %w", err)
 	}
 
-	printTx(tx)
-
+	return output.Print(output.NewTransaction(tx), func() {
+		printTx(tx)
+	})
 }
 
 func printTx(tx wallet.Transaction) {
+	amount, _ := utils.FormatAmount(tx.Amount, "ether", -1)
+	gasPrice, _ := utils.FormatAmount(tx.GasPrice, "gwei", -1)
+	fee, _ := utils.FormatAmount(new(big.Int).Mul(tx.GasUsed, tx.GasPrice), "ether", -1)
+
 	fmt.Printf("Transaction hash: %s\n", tx.Hash)
 	fmt.Printf("From: %s\n", tx.From)
 	fmt.Printf("To: %s\n", tx.To)
+	fmt.Printf("Amount: %s %s\n", amount, tx.Network.Symbol)
+	fmt.Printf("Gas: %s\n", tx.GasUsed)
+	fmt.Printf("Gas price: %s gwei\n", gasPrice)
+	fmt.Printf("Fee: %s %s\n", fee, tx.Network.Symbol)
+	output.PrintLink(tx.Network.TransactionUrl(tx.Hash))
+}
+
+// printTokenTx prints an ERC-20 transfer, whose amount is in the token's smallest unit.
+// The transaction is sent to the token contract, so the recipient is given apart.
+func printTokenTx(tx wallet.Transaction, token string, to string) {
+	gasPrice, _ := utils.FormatAmount(tx.GasPrice, "gwei", -1)
+	fee, _ := utils.FormatAmount(new(big.Int).Mul(tx.GasUsed, tx.GasPrice), "ether", -1)
+
+	fmt.Printf("Transaction hash: %s\n", tx.Hash)
+	fmt.Printf("Token: %s\n", token)
+	fmt.Printf("From: %s\n", tx.From)
+	fmt.Printf("To: %s\n", to)
 	fmt.Printf("Amount: %s\n", tx.Amount)
 	fmt.Printf("Gas: %s\n", tx.GasUsed)
-	fmt.Printf("Gas price: %s\n", tx.GasPrice)
+	fmt.Printf("Gas price: %s gwei\n", gasPrice)
+	fmt.Printf("Fee: %s %s\n", fee, tx.Network.Symbol)
+	output.PrintLink(tx.Network.TransactionUrl(tx.Hash))
+}
+
+var SendCmd = &cobra.Command{
+	Use:   "send",
+	Short: "Send is a palette that contains commands for sending funds in bulk",
+	Long:  `Send funds from the selected account and network to many recipients at once`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
 }
 
 var SendWeiCmd = &cobra.Command{
 	Use:   "sendwei",
 	Short: "Send wei from the selected address and network to the specified account",
 	Long:  `Send wei from the selected address and network to the specified account`,
-	Run: func(cmd *cobra.Command, args []string) {
-		sendWeiFunction()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendWeiFunction()
 	},
 }
 
@@ -116,19 +149,27 @@ var SendEthCmd = &cobra.Command{
 	Use:   "sendeth",
 	Short: "Send eth from the selected address and network to the specified account",
 	Long:  `Send eth from the selected address and network to the specified account`,
-	Run: func(cmd *cobra.Command, args []string) {
-		sendEthFunction()
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sendEthFunction()
 	},
 }
 
 func init() {
+	SendCmd.AddCommand(batchCmd)
+	batchCmd.Flags().StringVarP(&batch_file, "file", "f", "", "CSV file with address,amount,asset rows")
+	batchCmd.MarkFlagRequired("file")
+	batchCmd.Flags().StringVar(&batch_results, "results", "", "CSV file the results are written to, defaults to <file>.results.csv")
+	batchCmd.Flags().BoolVarP(&batch_yes, "yes", "y", false, "Send without asking for confirmation")
+
 	SendWeiCmd.Flags().StringVarP(&to_send, "to", "t", "", "Address to send the wei")
 	SendWeiCmd.MarkFlagRequired("to")
-	SendWeiCmd.Flags().StringVarP(&amount_send, "amt", "a", "", "Label for the network to be identified with")
+	SendWeiCmd.Flags().StringVarP(&amount_send, "amt", "a", "", "Amount to send in wei, or with a unit suffix like 20gwei")
 	SendWeiCmd.MarkFlagRequired("amt")
 
 	SendEthCmd.Flags().StringVarP(&to_send, "to", "t", "", "Address to send the ETH")
 	SendEthCmd.MarkFlagRequired("to")
-	SendEthCmd.Flags().StringVarP(&amount_send, "amt", "a", "", "Label for the network to be identified with")
-	SendEthCmd.MarkFlagRequired("amt")
+	SendEthCmd.Flags().StringVarP(&amount_send, "amt", "a", "", "Amount to send in ETH, or with a unit suffix like 20gwei")
+	SendEthCmd.Flags().BoolVar(&max_send, "max", false, "Send the whole balance of the account minus the transaction fee")
+	SendEthCmd.MarkFlagsOneRequired("amt", "max")
+	SendEthCmd.MarkFlagsMutuallyExclusive("amt", "max")
 }
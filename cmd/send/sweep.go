@@ -0,0 +1,109 @@
+package send
+
+import (
+	"fmt"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	to_sweep string
+)
+
+// sweepOutput is the json/yaml structure of a sweep: the token transfers in
+// the order they were sent, followed by the transfer of the native balance.
+type sweepOutput struct {
+	Tokens []output.Transaction `json:"tokens" yaml:"tokens"`
+	Native output.Transaction   `json:"native" yaml:"native"`
+}
+
+func sweepFunction() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	if !common.IsHexAddress(to_sweep) {
+		return fmt.Errorf("invalid destination address: %s", to_sweep)
+	}
+
+	output.Info("Sweeping account %s on network %s to %s", account.Label, network.Label, to_sweep)
+
+	result := sweepOutput{Tokens: []output.Transaction{}}
+
+	// Tokens go first, the ETH balance is still needed to pay for their
+	// transfers, so any failure stops the sweep before the ETH is sent
+	for _, token := range account.Tokens {
+		deployed, err := wallet.IsContract(token, network)
+		if err != nil {
+			return fmt.Errorf("failed to check token %s: %w", token, err)
+		}
+		if !deployed {
+			output.Info("Skipping token %s, which is not deployed on network %s", token, network.Label)
+			continue
+		}
+
+		balance, err := wallet.GetTokenBalance(token, account.Publicy, network)
+		if err != nil {
+			return fmt.Errorf("failed to get balance of token %s: %w", token, err)
+		}
+		if balance.Sign() == 0 {
+			continue
+		}
+
+		tx, err := wallet.SendToken(account.Privatey, token, to_sweep, balance, network)
+		if err != nil {
+			return fmt.Errorf("failed to send token %s: %w", token, err)
+		}
+		if output.IsTable() {
+			printTokenTx(tx, token, to_sweep)
+		}
+		// The token holds the contract, so to is the recipient as in the table
+		transfer := output.NewTransaction(tx)
+		transfer.To, transfer.Token = to_sweep, token
+		result.Tokens = append(result.Tokens, transfer)
+	}
+
+	tx, err := wallet.SendMaxETH(account.Privatey, to_sweep, network)
+	if err != nil {
+		return fmt.Errorf("failed to send ETH: %w", err)
+	}
+	result.Native = output.NewTransaction(tx)
+
+	return output.Print(result, func() {
+		printTx(tx)
+	})
+}
+
+var SweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "Move every tracked token and the whole ETH balance of the selected account to an address",
+	Long: `Transfers the full balance of every ERC-20 token tracked by the selected account, then sends
+the remaining ETH balance minus the transaction fee, leaving the account empty. Tokens not
+deployed on the network are skipped; any other failure stops the sweep before the ETH is sent,
+so that it can still pay for moving the tokens left.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return sweepFunction()
+	},
+}
+
+func init() {
+	SweepCmd.Flags().StringVarP(&to_sweep, "to", "t", "", "Address to sweep the funds to")
+	SweepCmd.MarkFlagRequired("to")
+}
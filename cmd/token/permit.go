@@ -0,0 +1,161 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/EliasManj/go-wallet/cmd/output"
+	"github.com/EliasManj/go-wallet/cmd/selection"
+	"github.com/EliasManj/go-wallet/utils"
+	"github.com/EliasManj/go-wallet/wallet"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var (
+	permit_token    string
+	permit_spender  string
+	permit_amount   string
+	permit_deadline string
+)
+
+// parseDeadline accepts a unix timestamp or a duration relative to now, such as "30m" or "24h".
+// A permit past its deadline cannot be used, so the deadline must be in the future.
+func parseDeadline(deadline string) (*big.Int, error) {
+	now := time.Now()
+	var at time.Time
+	if seconds, err := strconv.ParseInt(deadline, 10, 64); err == nil {
+		at = time.Unix(seconds, 0)
+	} else {
+		duration, err := time.ParseDuration(deadline)
+		if err != nil {
+			return nil, fmt.Errorf("deadline must be a unix timestamp or a duration like 30m: %q", deadline)
+		}
+		at = now.Add(duration)
+	}
+	if !at.After(now) {
+		return nil, fmt.Errorf("deadline %q is not in the future", deadline)
+	}
+	return big.NewInt(at.Unix()), nil
+}
+
+// permitOutput is the json/yaml structure of a signed permit. TypedData holds
+// the EIP-712 document exactly as a relayer would submit it.
+type permitOutput struct {
+	Token     string      `json:"token" yaml:"token"`
+	Owner     string      `json:"owner" yaml:"owner"`
+	Spender   string      `json:"spender" yaml:"spender"`
+	Value     string      `json:"value" yaml:"value"`
+	Nonce     string      `json:"nonce" yaml:"nonce"`
+	Deadline  string      `json:"deadline" yaml:"deadline"`
+	V         uint8       `json:"v" yaml:"v"`
+	R         string      `json:"r" yaml:"r"`
+	S         string      `json:"s" yaml:"s"`
+	Signature string      `json:"signature" yaml:"signature"`
+	TypedData interface{} `json:"typedData" yaml:"typedData"`
+}
+
+func signPermit() error {
+	store, err := wallet.OpenBoltStore(viper.GetString("database_file_path"))
+	if err != nil {
+		return fmt.Errorf("failed to open database: %w", err)
+	}
+	defer store.Close()
+
+	if !common.IsHexAddress(permit_token) {
+		return fmt.Errorf("invalid token address: %s", permit_token)
+	}
+	if !common.IsHexAddress(permit_spender) {
+		return fmt.Errorf("invalid spender address: %s", permit_spender)
+	}
+
+	account, err := selection.GetAccount(store)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	network, err := selection.GetNetwork(store)
+	if err != nil {
+		return fmt.Errorf("failed to get network: %w", err)
+	}
+
+	amount, err := utils.ParseTokenAmount(permit_amount)
+	if err != nil {
+		return fmt.Errorf("failed to parse amount: %w", err)
+	}
+
+	deadline, err := parseDeadline(permit_deadline)
+	if err != nil {
+		return fmt.Errorf("failed to parse deadline: %w", err)
+	}
+
+	permit, err := wallet.SignPermit(account.Privatey, permit_token, permit_spender, amount, deadline, network)
+	if err != nil {
+		return fmt.Errorf("failed to sign permit: %w", err)
+	}
+
+	typedData, err := json.MarshalIndent(permit.TypedData, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode typed data: %w", err)
+	}
+
+	// Decoded again so the yaml output uses the same keys as the json document
+	var document interface{}
+	if err := json.Unmarshal(typedData, &document); err != nil {
+		return fmt.Errorf("failed to encode typed data: %w", err)
+	}
+
+	result := permitOutput{
+		Token:     permit.Token,
+		Owner:     permit.Owner,
+		Spender:   permit.Spender,
+		Value:     permit.Value.String(),
+		Nonce:     permit.Nonce.String(),
+		Deadline:  permit.Deadline.String(),
+		V:         permit.V,
+		R:         permit.R,
+		S:         permit.S,
+		Signature: permit.Signature,
+		TypedData: document,
+	}
+	return output.Print(result, func() {
+		fmt.Printf("Token: %s\n", permit.Token)
+		fmt.Printf("Owner: %s\n", permit.Owner)
+		fmt.Printf("Spender: %s\n", permit.Spender)
+		fmt.Printf("Value: %s\n", permit.Value)
+		fmt.Printf("Nonce: %s\n", permit.Nonce)
+		fmt.Printf("Deadline: %s\n", permit.Deadline)
+		fmt.Printf("v: %d\n", permit.V)
+		fmt.Printf("r: %s\n", permit.R)
+		fmt.Printf("s: %s\n", permit.S)
+		fmt.Printf("Signature: %s\n", permit.Signature)
+		fmt.Println("Typed data:")
+		fmt.Println(string(typedData))
+	})
+}
+
+var permitCmd = &cobra.Command{
+	Use:   "permit",
+	Short: "Sign an EIP-2612 permit approving a spender without sending a transaction",
+	Long: `Reads the token's name, version, DOMAIN_SEPARATOR and the selected account's nonce, builds
+the EIP-712 Permit struct and signs it with the selected account. The v/r/s values and the full
+typed data are printed so a relayer can submit the permit on-chain.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return signPermit()
+	},
+}
+
+func init() {
+	TokenCmd.AddCommand(permitCmd)
+	permitCmd.Flags().StringVar(&permit_token, "token", "", "Address of the ERC-20 token")
+	permitCmd.MarkFlagRequired("token")
+	permitCmd.Flags().StringVar(&permit_spender, "spender", "", "Address allowed to spend the tokens")
+	permitCmd.MarkFlagRequired("spender")
+	permitCmd.Flags().StringVarP(&permit_amount, "amount", "a", "", "Allowance in the token's smallest unit")
+	permitCmd.MarkFlagRequired("amount")
+	permitCmd.Flags().StringVar(&permit_deadline, "deadline", "1h", "Unix timestamp or duration from now after which the permit expires")
+}
@@ -0,0 +1,14 @@
+package token
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var TokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Token is a palette that contains ERC-20 token based commands",
+	Long:  `Work with the ERC-20 tokens held by the selected account on the selected network`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Units maps the supported amount units to their number of decimals in wei.
+var Units = map[string]int{
+	"wei":    0,
+	"kwei":   3,
+	"mwei":   6,
+	"gwei":   9,
+	"szabo":  12,
+	"finney": 15,
+	"ether":  18,
+	"eth":    18,
+}
+
+// ParseAmount parses a decimal amount with an optional unit suffix, such as
+// "1.5ether", "20gwei" or "1000 wei", into an exact number of wei. Amounts
+// without a suffix are read in defaultUnit. The conversion is done on the
+// decimal digits, so no precision is lost, and amounts with more fractional
+// digits than the unit allows are rejected instead of being rounded.
+func ParseAmount(amount string, defaultUnit string) (*big.Int, error) {
+	value := strings.ToLower(strings.TrimSpace(amount))
+
+	// The longest matching suffix wins, so "gwei" is not read as "wei"
+	unit := defaultUnit
+	suffix := ""
+	for name := range Units {
+		if strings.HasSuffix(value, name) && len(name) > len(suffix) {
+			suffix = name
+		}
+	}
+	if suffix != "" {
+		unit = suffix
+		value = strings.TrimSpace(strings.TrimSuffix(value, suffix))
+	}
+
+	decimals, ok := Units[unit]
+	if !ok {
+		return nil, fmt.Errorf("unknown unit %q", unit)
+	}
+
+	whole, fraction, hasPoint := strings.Cut(value, ".")
+	if whole == "" && fraction == "" || hasPoint && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > decimals {
+		return nil, fmt.Errorf("amount %q has more than %d decimals, the precision of %s", amount, decimals, unit)
+	}
+
+	digits := whole + fraction + strings.Repeat("0", decimals-len(fraction))
+	wei, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid amount %q", amount)
+	}
+	return wei, nil
+}
+
+// ParseTokenAmount parses an amount in a token's smallest unit. Token
+// decimals are not the decimals of ether, so unit suffixes such as "ether"
+// and fractions are rejected instead of being scaled by 10^18.
+func ParseTokenAmount(amount string) (*big.Int, error) {
+	value := strings.TrimSpace(amount)
+	if value == "" || !isDigits(value) {
+		return nil, fmt.Errorf("invalid token amount %q, expected a whole number in the token's smallest unit", amount)
+	}
+	n, ok := new(big.Int).SetString(value, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid token amount %q", amount)
+	}
+	return n, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// FormatAmount formats an amount of wei in the given unit using exact decimal
+// arithmetic. With a non-negative precision exactly that many fractional digits
+// are shown and the rest is truncated, so a balance is never overstated; a
+// negative precision shows every significant digit. The integer part is
+// grouped with thousand separators.
+func FormatAmount(wei *big.Int, unit string, precision int) (string, error) {
+	decimals, ok := Units[strings.ToLower(unit)]
+	if !ok {
+		return "", fmt.Errorf("unknown unit %q", unit)
+	}
+
+	sign := ""
+	if wei.Sign() < 0 {
+		sign = "-"
+	}
+	digits := new(big.Int).Abs(wei).String()
+	if len(digits) <= decimals {
+		digits = strings.Repeat("0", decimals-len(digits)+1) + digits
+	}
+	whole := digits[:len(digits)-decimals]
+	fraction := digits[len(digits)-decimals:]
+
+	if precision < 0 {
+		fraction = strings.TrimRight(fraction, "0")
+	} else if precision < len(fraction) {
+		fraction = fraction[:precision]
+	} else {
+		fraction += strings.Repeat("0", precision-len(fraction))
+	}
+
+	formatted := sign + groupThousands(whole)
+	if fraction != "" {
+		formatted += "." + fraction
+	}
+	return formatted, nil
+}
+
+func groupThousands(digits string) string {
+	var grouped strings.Builder
+	for i, c := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(c)
+	}
+	return grouped.String()
+}
+
+// UnitLabel returns the name to display next to an amount in unit, which is
+// the network's symbol for whole ether.
+func UnitLabel(unit string, symbol string) string {
+	unit = strings.ToLower(unit)
+	if unit == "ether" || unit == "eth" {
+		return symbol
+	}
+	return unit
+}
@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseAmount(t *testing.T) {
+	cases := []struct {
+		amount      string
+		defaultUnit string
+		wei         string
+	}{
+		{"0.1", "ether", "100000000000000000"},
+		{"1.5ether", "wei", "1500000000000000000"},
+		{"1.5 ETH", "wei", "1500000000000000000"},
+		{"20gwei", "ether", "20000000000"},
+		{"1000wei", "ether", "1000"},
+		{"1000", "wei", "1000"},
+		{".5gwei", "wei", "500000000"},
+		{"1.000wei", "ether", "1"},
+		{"123456789.123456789123456789", "ether", "123456789123456789123456789"},
+	}
+	for _, c := range cases {
+		wei, err := ParseAmount(c.amount, c.defaultUnit)
+		require.NoError(t, err, c.amount)
+		require.Equal(t, c.wei, wei.String(), c.amount)
+	}
+}
+
+func TestParseAmountRejectsInvalid(t *testing.T) {
+	for _, amount := range []string{"", ".", "1.", "-1", "1e18", "abc", "1.5wei", "0.0000000001gwei", "0.0000000000000000001", "1.2.3", "10 dollars"} {
+		_, err := ParseAmount(amount, "ether")
+		require.Error(t, err, amount)
+	}
+}
+
+func TestParseTokenAmount(t *testing.T) {
+	amount, err := ParseTokenAmount(" 1000000 ")
+	require.NoError(t, err)
+	require.Equal(t, "1000000", amount.String())
+
+	for _, amount := range []string{"", "1ether", "20gwei", "5 wei", "1.5", "-1", "0x10"} {
+		_, err := ParseTokenAmount(amount)
+		require.Error(t, err, amount)
+	}
+}
+
+func TestFormatAmount(t *testing.T) {
+	wei, ok := new(big.Int).SetString("1234567891234567891234", 10)
+	require.True(t, ok)
+
+	cases := []struct {
+		wei       *big.Int
+		unit      string
+		precision int
+		formatted string
+	}{
+		{wei, "ether", -1, "1,234.567891234567891234"},
+		{wei, "ether", 2, "1,234.56"},
+		{wei, "ether", 0, "1,234"},
+		{wei, "gwei", 3, "1,234,567,891,234.567"},
+		{wei, "wei", -1, "1,234,567,891,234,567,891,234"},
+		{big.NewInt(1), "ether", -1, "0.000000000000000001"},
+		{big.NewInt(1), "ether", 4, "0.0000"},
+		{big.NewInt(0), "ether", -1, "0"},
+		{big.NewInt(1500000000), "gwei", 3, "1.500"},
+		{big.NewInt(-2000000000000000000), "ether", -1, "-2"},
+	}
+	for _, c := range cases {
+		formatted, err := FormatAmount(c.wei, c.unit, c.precision)
+		require.NoError(t, err)
+		require.Equal(t, c.formatted, formatted)
+	}
+
+	_, err := FormatAmount(wei, "btc", 2)
+	require.Error(t, err)
+}
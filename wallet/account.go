@@ -1,19 +1,23 @@
 package wallet
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
 
-	"github.com/boltdb/bolt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type Account struct {
-	Label    string `json:"label"`
-	Publicy  string `json:"pubic"`
-	Privatey string `json:"private"`
-	Tokens   []string
-	Selected bool
+	Label    string   `json:"label"`
+	Publicy  string   `json:"address"`
+	Privatey string   `json:"privateKey"`
+	Tokens   []string `json:"tokens"`
+	// Selected is derived from the store's selection and never persisted
+	Selected bool `json:"-"`
+	// ERC-1155 contracts tracked by the account, keyed by network label
+	MultiTokens map[string][]string `json:"multiTokens,omitempty"`
 }
 
 func GenerateKeyPair() (string, string, error) {
@@ -36,7 +40,7 @@ func GenerateKeyPair() (string, string, error) {
 	return privateKeyHex, address, nil
 }
 
-func CreateNewAccount(db *bolt.DB, label string) (Account, error) {
+func CreateNewAccount(store Store, label string) (Account, error) {
 	privateKey, publicKey, err := GenerateKeyPair()
 	if err != nil {
 		return Account{}, err
@@ -47,209 +51,154 @@ func CreateNewAccount(db *bolt.DB, label string) (Account, error) {
 		Publicy:  publicKey,
 		Privatey: privateKey,
 	}
-	return acc, ImportAccount(db, acc)
+	return acc, ImportAccount(store, acc)
 }
 
-func ImportAccount(db *bolt.DB, account Account) error {
-	err := db.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte("accounts"))
-		if err != nil {
-			return fmt.Errorf("create bucket: %s", err)
-		}
-
-		existing := bucket.Get([]byte(account.Label))
-		if existing != nil {
-			return fmt.Errorf("account with label %s already exists", account.Label)
-		}
-
-		accountJSON, err := json.Marshal(account)
-		if err != nil {
-			return fmt.Errorf("json marshal: %s", err)
-		}
-
-		return bucket.Put([]byte(account.Label), accountJSON)
-	})
-
-	if err != nil {
-		return err
-	}
-
-	return SelectAccount(db, account.Label)
+func ImportAccount(store Store, account Account) error {
+	return store.ImportAccount(account)
 }
 
-func AddTokenToAccount(db *bolt.DB, accountLabel, tokenAddress string) error {
-	err := db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("accounts"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
-
-		accountJSON := bucket.Get([]byte(accountLabel))
-		if accountJSON == nil {
-			return fmt.Errorf("account not found")
-		}
-
-		var account Account
-		err := json.Unmarshal(accountJSON, &account)
-		if err != nil {
-			return fmt.Errorf("json unmarshal: %s", err)
-		}
-
+func AddTokenToAccount(store Store, accountLabel, tokenAddress string) error {
+	return store.UpdateAccount(accountLabel, func(account *Account) error {
 		account.Tokens = append(account.Tokens, tokenAddress)
-
-		accountJSON, err = json.Marshal(account)
-		if err != nil {
-			return fmt.Errorf("json marshal: %s", err)
-		}
-
-		return bucket.Put([]byte(account.Label), accountJSON)
+		return nil
 	})
-
-	return err
 }
 
-func GetAccount(db *bolt.DB, label string) (Account, error) {
-	var account Account
-
-	err := db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("accounts"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
-
-		accountJSON := bucket.Get([]byte(label))
-		if accountJSON == nil {
-			return fmt.Errorf("account not found")
+func AddMultiTokenToAccount(store Store, accountLabel, networkLabel, contractAddress string) error {
+	return store.UpdateAccount(accountLabel, func(account *Account) error {
+		for _, tracked := range account.MultiTokens[networkLabel] {
+			if strings.EqualFold(tracked, contractAddress) {
+				return fmt.Errorf("contract %s is already tracked on network %s", contractAddress, networkLabel)
+			}
 		}
 
-		err := json.Unmarshal(accountJSON, &account)
-		if err != nil {
-			return fmt.Errorf("json unmarshal: %s", err)
+		if account.MultiTokens == nil {
+			account.MultiTokens = make(map[string][]string)
 		}
-
+		account.MultiTokens[networkLabel] = append(account.MultiTokens[networkLabel], contractAddress)
 		return nil
 	})
-
-	return account, err
 }
 
-func UpdateAccount(db *bolt.DB, account Account) error {
-	err := db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("accounts"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
-
-		accountJSON, err := json.Marshal(account)
-		if err != nil {
-			return fmt.Errorf("json marshal: %s", err)
-		}
-
-		return bucket.Put([]byte(account.Label), accountJSON)
-	})
-
-	return err
+// renameMultiTokens moves the contracts tracked on network label to newLabel
+// and reports whether there were any.
+func renameMultiTokens(account *Account, label, newLabel string) bool {
+	contracts, ok := account.MultiTokens[label]
+	if !ok {
+		return false
+	}
+	delete(account.MultiTokens, label)
+	account.MultiTokens[newLabel] = append(account.MultiTokens[newLabel], contracts...)
+	return true
 }
 
-func RemoveAccount(db *bolt.DB, label string) error {
-	err := db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("accounts"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
+func GetAccount(store Store, label string) (Account, error) {
+	return store.GetAccount(label)
+}
 
-		return bucket.Delete([]byte(label))
+func UpdateAccount(store Store, account Account) error {
+	return store.UpdateAccount(account.Label, func(stored *Account) error {
+		*stored = account
+		return nil
 	})
-
-	return err
 }
 
-func ListAccounts(db *bolt.DB) ([]Account, error) {
-	var accounts []Account
-	err := db.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte("accounts"))
-		if err != nil {
-			return fmt.Errorf("create bucket: %s", err)
-		}
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
-		err = bucket.ForEach(func(k, v []byte) error {
-			var account Account
-			err := json.Unmarshal(v, &account)
-			if err != nil {
-				return fmt.Errorf("json unmarshal: %s", err)
-			}
+// RemoveAccount deletes an account, selecting another one if it was selected.
+func RemoveAccount(store Store, label string) error {
+	return store.RemoveAccount(label)
+}
 
-			accounts = append(accounts, account)
-			return nil
-		})
+// ArchiveAccount moves an account to the archive, selecting another one if
+// it was selected.
+func ArchiveAccount(store Store, label string) error {
+	return store.ArchiveAccount(label)
+}
 
-		return err
-	})
+// UnarchiveAccount restores an archived account.
+func UnarchiveAccount(store Store, label string) error {
+	return store.UnarchiveAccount(label)
+}
 
-	return accounts, err
+func ListArchivedAccounts(store Store) ([]Account, error) {
+	return store.ListArchivedAccounts()
 }
 
-func SelectAccount(db *bolt.DB, label string) error {
-	// deselect current acccount
-	selectedAccount, err := GetSelectedAccount(db)
-	if err == nil {
-		selectedAccount.Selected = false
-		err = UpdateAccount(db, selectedAccount)
-		if err != nil {
-			return err
+// CheckEmptyAccount fails with ErrAccountHasBalance if the account holds the
+// native currency or one of its tracked ERC-20 tokens on any network of the
+// store. A network that cannot be queried fails the check as well, since the
+// balance there is unknown, and so do tracked ERC-1155 contracts, whose
+// holdings cannot be listed without their token ids.
+func CheckEmptyAccount(store Store, account Account) error {
+	for network, contracts := range account.MultiTokens {
+		if len(contracts) > 0 {
+			return fmt.Errorf("account tracks ERC-1155 contracts on %s, whose balances cannot be listed", network)
 		}
 	}
 
-	// select new account
-	account, err := GetAccount(db, label)
+	networks, err := store.ListNetworks()
 	if err != nil {
 		return err
 	}
-	err = db.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte("selected"))
+	for _, network := range networks {
+		balance, err := GetBalance(account.Publicy, network)
 		if err != nil {
-			return fmt.Errorf("create bucket: %s", err)
+			return fmt.Errorf("failed to check the balance on %s: %w", network.Label, err)
+		}
+		if balance.Sign() > 0 {
+			return fmt.Errorf("%w of %s wei on %s", ErrAccountHasBalance, balance, network.Label)
 		}
 
-		return bucket.Put([]byte("acc"), []byte(label))
-	})
-	if err != nil {
-		return err
+		for _, token := range account.Tokens {
+			// Tokens are not tracked per network, so most are not deployed
+			// on every network
+			deployed, err := IsContract(token, network)
+			if err != nil {
+				return fmt.Errorf("failed to check token %s on %s: %w", token, network.Label, err)
+			}
+			if !deployed {
+				continue
+			}
+			balance, err := GetTokenBalance(token, account.Publicy, network)
+			if err != nil {
+				return fmt.Errorf("failed to check the balance of token %s on %s: %w", token, network.Label, err)
+			}
+			if balance.Sign() > 0 {
+				return fmt.Errorf("%w of %s of token %s on %s", ErrAccountHasBalance, balance, token, network.Label)
+			}
+		}
 	}
-
-	account.Selected = true
-	return UpdateAccount(db, account)
+	return nil
 }
 
-func GetSelectedAccount(db *bolt.DB) (Account, error) {
-	var account Account
-	var label string
+func ListAccounts(store Store) ([]Account, error) {
+	return store.ListAccounts()
+}
 
-	err := db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("selected"))
-		if bucket == nil {
-			return nil
-		}
+func SelectAccount(store Store, label string) error {
+	return store.SelectAccount(label)
+}
 
-		label = string(bucket.Get([]byte("acc")))
-		if label == "" {
-			return fmt.Errorf("no account selected")
-		}
-		return nil
-	})
+func GetSelectedAccount(store Store) (Account, error) {
+	return store.GetSelectedAccount()
+}
 
-	if err != nil {
+// FindAccount returns the account with the label or address given, without
+// changing the selection.
+func FindAccount(store Store, labelOrAddress string) (Account, error) {
+	account, err := store.GetAccount(labelOrAddress)
+	if !errors.Is(err, ErrAccountNotFound) || !common.IsHexAddress(labelOrAddress) {
 		return account, err
 	}
-	account, err = GetAccount(db, label)
 
+	accounts, err := store.ListAccounts()
 	if err != nil {
-		return account, err
+		return Account{}, err
 	}
-
-	account.Selected = true
-
-	return account, nil
+	for _, account := range accounts {
+		if strings.EqualFold(account.Publicy, labelOrAddress) {
+			return account, nil
+		}
+	}
+	return Account{}, fmt.Errorf("%w: %s", ErrAccountNotFound, labelOrAddress)
 }
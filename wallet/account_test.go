@@ -3,6 +3,7 @@ package wallet
 
 import (
 	"fmt"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/crypto"
@@ -10,12 +11,12 @@ import (
 )
 
 func TestCreateNewAccount(t *testing.T) {
-	account, err := CreateNewAccount(db, "testnewacc")
+	account, err := CreateNewAccount(store, "testnewacc")
 	require.NoError(t, err)
 	require.Equal(t, "testnewacc", account.Label)
 	require.NotEmpty(t, account.Privatey)
 	require.NotEmpty(t, account.Publicy)
-	acc, err := GetAccount(db, account.Label)
+	acc, err := GetAccount(store, account.Label)
 	require.NoError(t, err)
 	require.Equal(t, account.Label, acc.Label)
 	require.Equal(t, account.Privatey, acc.Privatey)
@@ -49,9 +50,9 @@ func TestImportAccount(t *testing.T) {
 	private, public, err := GenerateKeyPair()
 	require.NoError(t, err)
 	account := Account{Label: "testimport", Publicy: public, Privatey: private}
-	err = ImportAccount(db, account)
+	err = ImportAccount(store, account)
 	require.NoError(t, err)
-	getAccount, err := GetAccount(db, account.Label)
+	getAccount, err := GetAccount(store, account.Label)
 	require.NoError(t, err)
 	require.Equal(t, account.Label, getAccount.Label)
 	require.Equal(t, account.Privatey, getAccount.Privatey)
@@ -60,36 +61,45 @@ func TestImportAccount(t *testing.T) {
 
 func TestListAccounts(t *testing.T) {
 	for i := 0; i < 5; i++ {
-		account, err := CreateNewAccount(db, fmt.Sprintf("testlistacc%d", i))
+		account, err := CreateNewAccount(store, fmt.Sprintf("testlistacc%d", i))
 		require.NoError(t, err)
 		require.NotEmpty(t, account)
 	}
 	for i := 0; i < 5; i++ {
 		label := fmt.Sprintf("testlistacc%d", i)
-		account, err := GetAccount(db, label)
+		account, err := GetAccount(store, label)
 		require.NoError(t, err)
 		require.Equal(t, label, account.Label)
 	}
 }
 
 func TestRemoveAccount(t *testing.T) {
-	account, err := CreateNewAccount(db, "testremoveacc")
+	account, err := CreateNewAccount(store, "testremoveacc")
 	require.NoError(t, err)
-	err = RemoveAccount(db, account.Label)
+	err = RemoveAccount(store, account.Label)
 	require.NoError(t, err)
-	allAccounts, err := ListAccounts(db)
+	allAccounts, err := ListAccounts(store)
 	require.NoError(t, err)
 	require.NotContains(t, allAccounts, account.Label)
+	_, err = GetAccount(store, account.Label)
+	require.ErrorIs(t, err, ErrAccountNotFound)
+}
+
+func TestImportAccountWithSameLabel(t *testing.T) {
+	account, err := CreateNewAccount(store, "testsamelabel")
+	require.NoError(t, err)
+	err = ImportAccount(store, account)
+	require.ErrorIs(t, err, ErrAccountExists)
 }
 
 func TestSelectAccount(t *testing.T) {
-	account1, err := CreateNewAccount(db, "testselectacc1")
+	account1, err := CreateNewAccount(store, "testselectacc1")
 	require.NoError(t, err)
-	_, err = CreateNewAccount(db, "testselectacc2")
+	_, err = CreateNewAccount(store, "testselectacc2")
 	require.NoError(t, err)
-	err = SelectAccount(db, account1.Label)
+	err = SelectAccount(store, account1.Label)
 	require.NoError(t, err)
-	activeAccount, err := GetSelectedAccount(db)
+	activeAccount, err := GetSelectedAccount(store)
 	require.NoError(t, err)
 	require.Equal(t, account1.Label, activeAccount.Label)
 	require.True(t, activeAccount.Selected)
@@ -98,51 +108,127 @@ func TestSelectAccount(t *testing.T) {
 }
 
 func TestOnlyOneSelectedAccount(t *testing.T) {
-	account1, err := CreateNewAccount(db, "testonlyoneacc1")
+	account1, err := CreateNewAccount(store, "testonlyoneacc1")
 	require.NoError(t, err)
-	account2, err := CreateNewAccount(db, "testonlyoneacc2")
+	account2, err := CreateNewAccount(store, "testonlyoneacc2")
 	require.NoError(t, err)
-	account3, err := CreateNewAccount(db, "testonlyoneacc3")
+	account3, err := CreateNewAccount(store, "testonlyoneacc3")
 	require.NoError(t, err)
 
-	err = SelectAccount(db, account1.Label)
+	err = SelectAccount(store, account1.Label)
 	require.NoError(t, err)
-	err = SelectAccount(db, account2.Label)
+	err = SelectAccount(store, account2.Label)
 	require.NoError(t, err)
-	err = SelectAccount(db, account3.Label)
+	err = SelectAccount(store, account3.Label)
 	require.NoError(t, err)
 
-	activeAccount, err := GetSelectedAccount(db)
+	activeAccount, err := GetSelectedAccount(store)
 	require.NoError(t, err)
 	require.Equal(t, account3.Label, activeAccount.Label)
 	require.True(t, activeAccount.Selected)
 	require.Equal(t, account3.Privatey, activeAccount.Privatey)
 	require.Equal(t, account3.Publicy, activeAccount.Publicy)
 
-	acc1, err := GetAccount(db, account1.Label)
+	acc1, err := GetAccount(store, account1.Label)
 	require.NoError(t, err)
 	require.False(t, acc1.Selected)
 
-	acc2, err := GetAccount(db, account2.Label)
+	acc2, err := GetAccount(store, account2.Label)
 	require.NoError(t, err)
 	require.False(t, acc2.Selected)
 }
 
 func TestCreateTwoAccountsSelect(t *testing.T) {
-	account1, err := CreateNewAccount(db, "testcreatetwoaccountsselect1")
+	account1, err := CreateNewAccount(store, "testcreatetwoaccountsselect1")
 	require.NoError(t, err)
-	account2, err := CreateNewAccount(db, "testcreatetwoaccountsselect2")
+	account2, err := CreateNewAccount(store, "testcreatetwoaccountsselect2")
 	require.NoError(t, err)
 
-	activeAccount, err := GetSelectedAccount(db)
+	activeAccount, err := GetSelectedAccount(store)
 	require.NoError(t, err)
 	require.Equal(t, account2.Label, activeAccount.Label)
 
-	acc1, err := GetAccount(db, account1.Label)
+	acc1, err := GetAccount(store, account1.Label)
 	require.NoError(t, err)
 	require.False(t, acc1.Selected)
 
-	acc2, err := GetAccount(db, account2.Label)
+	acc2, err := GetAccount(store, account2.Label)
 	require.NoError(t, err)
 	require.True(t, acc2.Selected)
 }
+
+func TestFindAccount(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.ImportAccount(Account{Label: "a", Publicy: "0x7cfFa60D0c7ba62704f593644A4A95E6067Ce5d6"}))
+	require.NoError(t, store.ImportAccount(Account{Label: "b", Publicy: "0x22fAbc544025f6A20214dF6fFa9278BC247391eE"}))
+
+	account, err := FindAccount(store, "a")
+	require.NoError(t, err)
+	require.Equal(t, "a", account.Label)
+
+	account, err = FindAccount(store, "0x7cffa60d0c7ba62704f593644a4a95e6067ce5d6")
+	require.NoError(t, err)
+	require.Equal(t, "a", account.Label)
+	require.False(t, account.Selected)
+
+	_, err = FindAccount(store, "0x0000000000000000000000000000000000000001")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	// Finding an account never changes the selection
+	selected, err := GetSelectedAccount(store)
+	require.NoError(t, err)
+	require.Equal(t, "b", selected.Label)
+}
+
+// newHoldingServer is a node where the account holds balance wei and every
+// address is a token contract reporting tokenBalance.
+func newHoldingServer(t *testing.T, balance string, tokenBalance int64) *httptest.Server {
+	return newRPCServer(t, func(method string) string {
+		switch method {
+		case "eth_chainId":
+			return `"0x1"`
+		case "eth_getBalance":
+			return `"` + balance + `"`
+		case "eth_getCode":
+			return `"0x6080"`
+		case "eth_call":
+			return fmt.Sprintf(`"0x%064x"`, tokenBalance)
+		}
+		return ""
+	})
+}
+
+func TestCheckEmptyAccount(t *testing.T) {
+	store := NewMemoryStore()
+	account := Account{Label: "a", Publicy: "0x0000000000000000000000000000000000000001"}
+	require.NoError(t, CheckEmptyAccount(store, account))
+
+	require.NoError(t, store.AddNetwork(Network{Label: "empty", ChainId: 1, RpcUrl: newHoldingServer(t, "0x0", 0).URL}))
+	require.NoError(t, CheckEmptyAccount(store, account))
+
+	require.NoError(t, store.AddNetwork(Network{Label: "funded", ChainId: 1, RpcUrl: newHoldingServer(t, "0x2a", 0).URL}))
+	require.ErrorIs(t, CheckEmptyAccount(store, account), ErrAccountHasBalance)
+	require.NoError(t, store.DeleteNetwork("funded"))
+
+	// Tracked ERC-20 tokens are checked too
+	require.NoError(t, store.AddNetwork(Network{Label: "tokens", ChainId: 1, RpcUrl: newHoldingServer(t, "0x0", 5).URL}))
+	require.NoError(t, CheckEmptyAccount(store, account))
+	account.Tokens = []string{"0x0000000000000000000000000000000000000002"}
+	err := CheckEmptyAccount(store, account)
+	require.ErrorIs(t, err, ErrAccountHasBalance)
+	require.ErrorContains(t, err, "token 0x0000000000000000000000000000000000000002 on tokens")
+	require.NoError(t, store.DeleteNetwork("tokens"))
+
+	// ERC-1155 holdings cannot be listed, so they are not assumed to be empty
+	account.MultiTokens = map[string][]string{"empty": {"0x0000000000000000000000000000000000000003"}}
+	require.Error(t, CheckEmptyAccount(store, account))
+	account.MultiTokens = nil
+
+	// A balance that cannot be read is not assumed to be zero
+	down := httptest.NewServer(nil)
+	down.Close()
+	require.NoError(t, store.AddNetwork(Network{Label: "down", ChainId: 1, RpcUrl: down.URL}))
+	err = CheckEmptyAccount(store, account)
+	require.Error(t, err)
+	require.NotErrorIs(t, err, ErrAccountHasBalance)
+}
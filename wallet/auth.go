@@ -0,0 +1,227 @@
+package wallet
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Prefixes of secret references. Credentials are stored as references and
+// only resolved when connecting, so the network record never holds them.
+const (
+	envReference  = "env:"
+	fileReference = "file:"
+)
+
+// BasicAuth holds the credentials of an endpoint using HTTP basic
+// authentication. Password is a secret reference.
+type BasicAuth struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// IsSecretReference reports whether value is an env:NAME or file:PATH
+// reference.
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, envReference) && len(value) > len(envReference) ||
+		strings.HasPrefix(value, fileReference) && len(value) > len(fileReference)
+}
+
+// ResolveSecret returns the value a reference points to: env:NAME reads an
+// environment variable and file:PATH a file, without its trailing newline.
+func ResolveSecret(reference string) (string, error) {
+	switch {
+	case !IsSecretReference(reference):
+		return "", fmt.Errorf("%q is not a secret reference, use env:NAME or file:PATH", reference)
+	case strings.HasPrefix(reference, envReference):
+		name := strings.TrimPrefix(reference, envReference)
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s is not set", ErrSecretNotFound, name)
+		}
+		return value, nil
+	default:
+		path := strings.TrimPrefix(reference, fileReference)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("%w: %v", ErrSecretNotFound, err)
+		}
+		return strings.TrimRight(string(data), "\r\n"), nil
+	}
+}
+
+// validateCredentials checks that every header value and the basic auth
+// password are secret references.
+func (n Network) validateCredentials() error {
+	for name, value := range n.Headers {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if !IsSecretReference(value) {
+			return fmt.Errorf("header %s must be set to a secret reference (env:NAME or file:PATH)", name)
+		}
+	}
+	if n.BasicAuth != nil && !IsSecretReference(n.BasicAuth.Password) {
+		return fmt.Errorf("basic auth password must be a secret reference (env:NAME or file:PATH)")
+	}
+	return nil
+}
+
+// requestHeaders resolves the headers and basic auth credentials of the
+// network.
+func (n Network) requestHeaders() (http.Header, error) {
+	headers := make(http.Header)
+	for name, reference := range n.Headers {
+		value, err := ResolveSecret(reference)
+		if err != nil {
+			return nil, fmt.Errorf("header %s: %w", name, err)
+		}
+		headers.Set(name, value)
+	}
+	if n.BasicAuth != nil {
+		password, err := ResolveSecret(n.BasicAuth.Password)
+		if err != nil {
+			return nil, fmt.Errorf("basic auth: %w", err)
+		}
+		credentials := base64.StdEncoding.EncodeToString([]byte(n.BasicAuth.Username + ":" + password))
+		headers.Set("Authorization", "Basic "+credentials)
+	}
+	return headers, nil
+}
+
+// urlReference matches the ${env:NAME} and ${file:PATH} placeholders an
+// endpoint URL may hold in place of an API key, for providers that take the
+// key in the URL.
+var urlReference = regexp.MustCompile(`\$\{((?:env|file):[^}]+)\}`)
+
+// resolveEndpoint replaces the placeholders of an endpoint URL with the
+// secrets they reference.
+func resolveEndpoint(endpoint string) (string, error) {
+	var resolveErr error
+	resolved := urlReference.ReplaceAllStringFunc(endpoint, func(placeholder string) string {
+		value, err := ResolveSecret(urlReference.FindStringSubmatch(placeholder)[1])
+		if err != nil && resolveErr == nil {
+			resolveErr = fmt.Errorf("endpoint %s: %w", RedactUrl(endpoint), err)
+		}
+		return url.PathEscape(value)
+	})
+	return resolved, resolveErr
+}
+
+// dial connects to an endpoint of the network with its credentials.
+func dial(endpoint string, network Network) (*ethclient.Client, error) {
+	headers, err := network.requestHeaders()
+	if err != nil {
+		return nil, err
+	}
+	endpoint, err = resolveEndpoint(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	client, err := rpc.DialOptions(context.Background(), endpoint, rpc.WithHeaders(headers))
+	if err != nil {
+		return nil, err
+	}
+	return ethclient.NewClient(client), nil
+}
+
+// looksLikeKey reports whether a part of a URL looks like an API key: a long
+// run of letters and digits such as the Infura or Alchemy keys that end
+// their endpoint URLs.
+func looksLikeKey(value string) bool {
+	if len(value) < 20 {
+		return false
+	}
+	letters, digits := false, false
+	for _, c := range value {
+		switch {
+		case c >= '0' && c <= '9':
+			digits = true
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			letters = true
+		case c != '-' && c != '_':
+			return false
+		}
+	}
+	return letters && digits
+}
+
+// redactKeys hides the path segments and query values of a URL that look
+// like API keys. Placeholders are kept, as they only name where the key is.
+func redactKeys(rest string) (string, bool) {
+	path, query, hasQuery := strings.Cut(rest, "?")
+	redacted := false
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if looksLikeKey(segment) {
+			segments[i], redacted = "xxxxx", true
+		}
+	}
+	rest = strings.Join(segments, "/")
+
+	if hasQuery {
+		params := strings.Split(query, "&")
+		for i, param := range params {
+			name, value, ok := strings.Cut(param, "=")
+			if ok && looksLikeKey(value) {
+				params[i], redacted = name+"=xxxxx", true
+			}
+		}
+		rest += "?" + strings.Join(params, "&")
+	}
+	return rest, redacted
+}
+
+// splitUrl splits an endpoint URL after its host, so that the path and query
+// are handled as written, placeholders included.
+func splitUrl(endpoint string) (*url.URL, string, bool) {
+	u, err := url.Parse(endpoint)
+	if err != nil || u.Host == "" {
+		return nil, "", false
+	}
+	start := len(u.Scheme) + len("://")
+	if i := strings.IndexAny(endpoint[start:], "/?#"); i >= 0 {
+		return u, endpoint[start+i:], true
+	}
+	return u, "", true
+}
+
+// validateUrlCredentials rejects endpoint URLs holding a password or what
+// looks like an API key, which would be stored in the database as is.
+func validateUrlCredentials(endpoint string) error {
+	u, rest, ok := splitUrl(endpoint)
+	if !ok {
+		return nil
+	}
+	if _, ok := u.User.Password(); ok {
+		return fmt.Errorf("rpcUrl %s holds a password, give it with basic auth as a secret reference instead", RedactUrl(endpoint))
+	}
+	if _, redacted := redactKeys(rest); redacted {
+		return fmt.Errorf("rpcUrl %s seems to hold an API key, put ${env:NAME} or ${file:PATH} in its place", RedactUrl(endpoint))
+	}
+	return nil
+}
+
+// RedactUrl hides the password of credentials embedded in an endpoint URL and
+// the parts of its path and query that look like API keys.
+func RedactUrl(endpoint string) string {
+	u, rest, ok := splitUrl(endpoint)
+	if !ok {
+		return endpoint
+	}
+	authority := endpoint[:len(endpoint)-len(rest)]
+	if _, ok := u.User.Password(); ok {
+		authority = u.Scheme + "://" + url.UserPassword(u.User.Username(), "xxxxx").String() + "@" + u.Host
+	}
+	rest, _ = redactKeys(rest)
+	return authority + rest
+}
@@ -0,0 +1,261 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	backupFormat = "eth-wallet-backup"
+	// BackupVersion 2 added archived accounts and networks
+	BackupVersion = 2
+)
+
+// scrypt parameters of new backups. They are stored in every archive, so
+// they can be raised later without breaking older backups.
+const (
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+)
+
+// scryptN is a variable so that tests can use a cheaper cost.
+var scryptN = 1 << 18
+
+// ErrBackupDecrypt is returned when a backup cannot be decrypted, either
+// because the passphrase is wrong or because the archive was altered.
+var ErrBackupDecrypt = errors.New("wrong passphrase or corrupted backup")
+
+// Backup holds everything a wallet stores: the accounts with their keys and
+// tracked tokens, the networks, archived accounts and networks and the
+// selection. The wallet keeps no address book or transaction history, so
+// there is none to back up. It is encrypted as a whole by EncryptBackup.
+type Backup struct {
+	Version         int       `json:"version"`
+	SchemaVersion   int       `json:"schemaVersion"`
+	Created         time.Time `json:"created"`
+	Accounts        []Account `json:"accounts"`
+	Networks        []Network `json:"networks"`
+	SelectedAccount string    `json:"selectedAccount,omitempty"`
+	SelectedNetwork string    `json:"selectedNetwork,omitempty"`
+	// Archived accounts keep their private keys, so they are backed up too
+	ArchivedAccounts []Account `json:"archivedAccounts,omitempty"`
+	ArchivedNetworks []Network `json:"archivedNetworks,omitempty"`
+}
+
+// backupArchive is the file format of an encrypted backup. The header fields
+// are authenticated along with the ciphertext.
+type backupArchive struct {
+	Format     string    `json:"format"`
+	Version    int       `json:"version"`
+	KDF        backupKDF `json:"kdf"`
+	Nonce      []byte    `json:"nonce"`
+	Ciphertext []byte    `json:"ciphertext"`
+}
+
+type backupKDF struct {
+	Name string `json:"name"`
+	Salt []byte `json:"salt"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+}
+
+func (a backupArchive) additionalData() []byte {
+	return []byte(strings.Join([]string{
+		a.Format,
+		strconv.Itoa(a.Version),
+		a.KDF.Name,
+		strconv.Itoa(a.KDF.N),
+		strconv.Itoa(a.KDF.R),
+		strconv.Itoa(a.KDF.P),
+	}, "|"))
+}
+
+func backupCipher(passphrase string, kdf backupKDF) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), kdf.Salt, kdf.N, kdf.R, kdf.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// NewBackup reads every account and network of a store, archived ones
+// included.
+func NewBackup(store Store) (Backup, error) {
+	backup := Backup{
+		Version:       BackupVersion,
+		SchemaVersion: SchemaVersion,
+		Created:       time.Now().UTC(),
+	}
+
+	accounts, err := store.ListAccounts()
+	if err != nil {
+		return Backup{}, err
+	}
+	networks, err := store.ListNetworks()
+	if err != nil {
+		return Backup{}, err
+	}
+	backup.ArchivedAccounts, err = store.ListArchivedAccounts()
+	if err != nil {
+		return Backup{}, err
+	}
+	backup.ArchivedNetworks, err = store.ListArchivedNetworks()
+	if err != nil {
+		return Backup{}, err
+	}
+
+	for _, account := range accounts {
+		if account.Selected {
+			backup.SelectedAccount = account.Label
+		}
+	}
+	for _, network := range networks {
+		if network.Selected {
+			backup.SelectedNetwork = network.Label
+		}
+	}
+	backup.Accounts = append([]Account{}, accounts...)
+	backup.Networks = append([]Network{}, networks...)
+	return backup, nil
+}
+
+// EncryptBackup encrypts a backup with a key derived from passphrase with
+// scrypt, using AES-256-GCM.
+func EncryptBackup(backup Backup, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase must not be empty")
+	}
+
+	plaintext, err := json.Marshal(backup)
+	if err != nil {
+		return nil, fmt.Errorf("json marshal: %s", err)
+	}
+
+	archive := backupArchive{
+		Format:  backupFormat,
+		Version: BackupVersion,
+		KDF:     backupKDF{Name: "scrypt", Salt: make([]byte, 32), N: scryptN, R: scryptR, P: scryptP},
+	}
+	if _, err := rand.Read(archive.KDF.Salt); err != nil {
+		return nil, err
+	}
+
+	aead, err := backupCipher(passphrase, archive.KDF)
+	if err != nil {
+		return nil, err
+	}
+	archive.Nonce = make([]byte, aead.NonceSize())
+	if _, err := rand.Read(archive.Nonce); err != nil {
+		return nil, err
+	}
+	archive.Ciphertext = aead.Seal(nil, archive.Nonce, plaintext, archive.additionalData())
+
+	return json.MarshalIndent(archive, "", "  ")
+}
+
+// DecryptBackup decrypts and validates a backup. Nothing is returned unless
+// the archive is authentic and its contents are consistent.
+func DecryptBackup(data []byte, passphrase string) (Backup, error) {
+	var archive backupArchive
+	err := json.Unmarshal(data, &archive)
+	if err != nil || archive.Format != backupFormat {
+		return Backup{}, fmt.Errorf("not a wallet backup")
+	}
+	if archive.Version > BackupVersion {
+		return Backup{}, fmt.Errorf("backup version %d is newer than supported version %d", archive.Version, BackupVersion)
+	}
+	if archive.KDF.Name != "scrypt" {
+		return Backup{}, fmt.Errorf("unsupported key derivation %q", archive.KDF.Name)
+	}
+
+	aead, err := backupCipher(passphrase, archive.KDF)
+	if err != nil {
+		return Backup{}, err
+	}
+	if len(archive.Nonce) != aead.NonceSize() {
+		return Backup{}, ErrBackupDecrypt
+	}
+	plaintext, err := aead.Open(nil, archive.Nonce, archive.Ciphertext, archive.additionalData())
+	if err != nil {
+		return Backup{}, ErrBackupDecrypt
+	}
+
+	var backup Backup
+	err = json.Unmarshal(plaintext, &backup)
+	if err != nil {
+		return Backup{}, fmt.Errorf("json unmarshal: %s", err)
+	}
+	return backup, backup.Validate()
+}
+
+// validateAccounts checks that labels are set and unique and that every
+// private key matches its address.
+func validateAccounts(accounts []Account) (map[string]bool, error) {
+	labels := make(map[string]bool)
+	for _, account := range accounts {
+		if account.Label == "" || labels[account.Label] {
+			return nil, fmt.Errorf("backup has an empty or duplicate account label %q", account.Label)
+		}
+		labels[account.Label] = true
+
+		address, err := GetAddressFromPrivateKey(account.Privatey)
+		if err != nil || !strings.EqualFold(address, account.Publicy) {
+			return nil, fmt.Errorf("backup account %s: private key does not match address %s", account.Label, account.Publicy)
+		}
+	}
+	return labels, nil
+}
+
+func validateNetworkLabels(networks []Network) (map[string]bool, error) {
+	labels := make(map[string]bool)
+	for _, network := range networks {
+		if network.Label == "" || labels[network.Label] {
+			return nil, fmt.Errorf("backup has an empty or duplicate network label %q", network.Label)
+		}
+		labels[network.Label] = true
+	}
+	return labels, nil
+}
+
+// Validate checks that labels are set and unique, separately for archived
+// records, that the selections exist and that every private key matches its
+// address.
+func (b Backup) Validate() error {
+	accounts, err := validateAccounts(b.Accounts)
+	if err != nil {
+		return err
+	}
+	networks, err := validateNetworkLabels(b.Networks)
+	if err != nil {
+		return err
+	}
+	if _, err := validateAccounts(b.ArchivedAccounts); err != nil {
+		return err
+	}
+	if _, err := validateNetworkLabels(b.ArchivedNetworks); err != nil {
+		return err
+	}
+
+	if b.SelectedAccount != "" && !accounts[b.SelectedAccount] {
+		return fmt.Errorf("backup selects missing account %s", b.SelectedAccount)
+	}
+	if b.SelectedNetwork != "" && !networks[b.SelectedNetwork] {
+		return fmt.Errorf("backup selects missing network %s", b.SelectedNetwork)
+	}
+	return nil
+}
@@ -0,0 +1,145 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBackup(t *testing.T) (Backup, *MemoryStore) {
+	store := NewMemoryStore()
+	_, err := CreateNewAccount(store, "main")
+	require.NoError(t, err)
+	_, err = CreateNewAccount(store, "savings")
+	require.NoError(t, err)
+	require.NoError(t, store.AddNetwork(Network{Label: "local", ChainId: 1337, Symbol: "ETH", RpcUrl: "http://localhost:8545"}))
+	require.NoError(t, AddMultiTokenToAccount(store, "main", "local", "0x0000000000000000000000000000000000000001"))
+	require.NoError(t, store.SelectAccount("main"))
+
+	backup, err := NewBackup(store)
+	require.NoError(t, err)
+	return backup, store
+}
+
+func TestBackupRoundTrip(t *testing.T) {
+	scryptN = 1 << 10
+	backup, _ := newTestBackup(t)
+
+	data, err := EncryptBackup(backup, "correct horse")
+	require.NoError(t, err)
+	require.False(t, bytes.Contains(data, []byte(backup.Accounts[0].Privatey)))
+
+	decrypted, err := DecryptBackup(data, "correct horse")
+	require.NoError(t, err)
+	require.Equal(t, "main", decrypted.SelectedAccount)
+	require.Equal(t, "local", decrypted.SelectedNetwork)
+	require.Len(t, decrypted.Accounts, 2)
+	require.Equal(t, backup.Accounts[0].Privatey, decrypted.Accounts[0].Privatey)
+
+	_, err = DecryptBackup(data, "wrong")
+	require.ErrorIs(t, err, ErrBackupDecrypt)
+
+	// Changing an authenticated header field is detected
+	var archive backupArchive
+	require.NoError(t, json.Unmarshal(data, &archive))
+	archive.KDF.P = 2
+	tampered, err := json.Marshal(archive)
+	require.NoError(t, err)
+	_, err = DecryptBackup(tampered, "correct horse")
+	require.ErrorIs(t, err, ErrBackupDecrypt)
+}
+
+func TestRestoreIntoEmptyStore(t *testing.T) {
+	backup, _ := newTestBackup(t)
+	store := NewMemoryStore()
+
+	result, err := RestoreBackup(store, backup, RestoreOptions{})
+	require.NoError(t, err)
+	require.Len(t, result.Accounts, 2)
+	require.Equal(t, RestoreAdded, result.Accounts[0].Action)
+
+	selected, err := store.GetSelectedAccount()
+	require.NoError(t, err)
+	require.Equal(t, "main", selected.Label)
+
+	// A wallet that is not empty needs merge
+	_, err = RestoreBackup(store, backup, RestoreOptions{})
+	require.Error(t, err)
+
+	// Restoring the same records again changes nothing
+	result, err = RestoreBackup(store, backup, RestoreOptions{Merge: true})
+	require.NoError(t, err)
+	require.Equal(t, RestoreUnchanged, result.Accounts[0].Action)
+	require.Equal(t, RestoreUnchanged, result.Networks[0].Action)
+}
+
+func TestRestoreMergeConflicts(t *testing.T) {
+	backup, _ := newTestBackup(t)
+
+	store := NewMemoryStore()
+	_, err := CreateNewAccount(store, "main")
+	require.NoError(t, err)
+	require.NoError(t, store.AddNetwork(Network{Label: "local", ChainId: 1, Symbol: "ETH", RpcUrl: "http://localhost:8545"}))
+
+	// Conflicts fail before anything is written
+	_, err = RestoreBackup(store, backup, RestoreOptions{Merge: true})
+	require.Error(t, err)
+	accounts, err := store.ListAccounts()
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+
+	result, err := RestoreBackup(store, backup, RestoreOptions{Merge: true, OnConflict: ConflictRename})
+	require.NoError(t, err)
+	require.Equal(t, RestoreRenamed, result.Accounts[0].Action)
+	require.Equal(t, "main-restored", result.Accounts[0].Label)
+	require.Equal(t, RestoreAdded, result.Accounts[1].Action)
+	require.Equal(t, "local-restored", result.Networks[0].Label)
+
+	// Tracked contracts follow the renamed network
+	restored, err := store.GetAccount("main-restored")
+	require.NoError(t, err)
+	require.Len(t, restored.MultiTokens["local-restored"], 1)
+
+	// The selection of the wallet is kept when merging
+	selected, err := store.GetSelectedAccount()
+	require.NoError(t, err)
+	require.Equal(t, "main", selected.Label)
+}
+
+func TestBackupArchived(t *testing.T) {
+	_, source := newTestBackup(t)
+	require.NoError(t, source.ArchiveAccount("savings"))
+	require.NoError(t, source.AddNetwork(Network{Label: "old", ChainId: 5, Symbol: "ETH", RpcUrl: "http://localhost:8545"}))
+	require.NoError(t, source.ArchiveNetwork("old"))
+
+	// Archived accounts keep their keys, so leaving them out would lose them
+	backup, err := NewBackup(source)
+	require.NoError(t, err)
+	require.Len(t, backup.Accounts, 1)
+	require.Len(t, backup.ArchivedAccounts, 1)
+	require.Len(t, backup.ArchivedNetworks, 1)
+
+	store := NewMemoryStore()
+	result, err := RestoreBackup(store, backup, RestoreOptions{})
+	require.NoError(t, err)
+	require.Equal(t, RestoreAdded, result.ArchivedAccounts[0].Action)
+
+	archived, err := store.ListArchivedAccounts()
+	require.NoError(t, err)
+	require.Equal(t, backup.ArchivedAccounts, archived)
+	networks, err := store.ListArchivedNetworks()
+	require.NoError(t, err)
+	require.Equal(t, "old", networks[0].Label)
+	_, err = store.GetAccount("savings")
+	require.ErrorIs(t, err, ErrAccountNotFound)
+
+	// An archived account with the same label and other content conflicts
+	backup.ArchivedAccounts[0].Tokens = []string{"0x0000000000000000000000000000000000000002"}
+	_, err = RestoreBackup(store, backup, RestoreOptions{Merge: true})
+	require.ErrorContains(t, err, "account savings")
+	result, err = RestoreBackup(store, backup, RestoreOptions{Merge: true, OnConflict: ConflictRename})
+	require.NoError(t, err)
+	require.Equal(t, "savings-restored", result.ArchivedAccounts[0].Label)
+}
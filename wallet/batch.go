@@ -0,0 +1,250 @@
+package wallet
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/EliasManj/go-wallet/utils"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Statuses of a payment in a batch results file
+const (
+	PaymentPending   = "pending"
+	PaymentConfirmed = "confirmed"
+	PaymentReverted  = "reverted"
+	PaymentFailed    = "failed"
+)
+
+// Payment is a single row of a batch payments file. Asset is empty for the
+// native currency, otherwise it holds the address of an ERC-20 token.
+type Payment struct {
+	Row     int
+	Address string
+	Amount  *big.Int
+	Asset   string
+}
+
+// PaymentResult records the outcome of a payment. Nonce and Hash are set
+// as soon as the transaction is signed, before it is broadcast, so that an
+// interrupted batch can be resumed without paying anyone twice.
+type PaymentResult struct {
+	Payment
+	Nonce  *uint64
+	Hash   string
+	Status string
+	Error  string
+}
+
+var paymentResultsHeader = []string{"row", "address", "amount", "asset", "nonce", "hash", "status", "error"}
+
+// IsNative reports whether the payment is in the network's native currency.
+func (p Payment) IsNative() bool {
+	return p.Asset == ""
+}
+
+// ParsePayments reads a payments CSV with address, amount and asset columns.
+// An optional header row is skipped. Native amounts default to ETH and
+// accept the unit suffixes of utils.ParseAmount; token amounts are whole
+// numbers in the token's smallest unit. An empty asset, "ETH" or the network
+// symbol select the native currency. Every row is validated before anything
+// is returned.
+func ParsePayments(r io.Reader, symbol string) ([]Payment, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read payments: %v", err)
+	}
+
+	var payments []Payment
+	var errs []string
+	for i, record := range records {
+		row := i + 1
+		if i == 0 && len(record) > 0 && strings.EqualFold(strings.TrimSpace(record[0]), "address") {
+			continue
+		}
+		if len(record) < 2 || len(record) > 3 {
+			errs = append(errs, fmt.Sprintf("row %d: expected address,amount[,asset]", row))
+			continue
+		}
+
+		address := strings.TrimSpace(record[0])
+		if !common.IsHexAddress(address) {
+			errs = append(errs, fmt.Sprintf("row %d: invalid address %q", row, address))
+			continue
+		}
+
+		asset := ""
+		if len(record) == 3 {
+			asset = strings.TrimSpace(record[2])
+		}
+		if strings.EqualFold(asset, "ETH") || (symbol != "" && strings.EqualFold(asset, symbol)) {
+			asset = ""
+		}
+		if asset != "" {
+			if !common.IsHexAddress(asset) {
+				errs = append(errs, fmt.Sprintf("row %d: asset must be %s or a token address, got %q", row, symbol, asset))
+				continue
+			}
+			asset = common.HexToAddress(asset).String()
+		}
+
+		amount, err := parsePaymentAmount(strings.TrimSpace(record[1]), asset == "")
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("row %d: %v", row, err))
+			continue
+		}
+
+		payments = append(payments, Payment{
+			Row:     row,
+			Address: common.HexToAddress(address).String(),
+			Amount:  amount,
+			Asset:   asset,
+		})
+	}
+
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("invalid payments:\n%s", strings.Join(errs, "\n"))
+	}
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("no payments found")
+	}
+	return payments, nil
+}
+
+func parsePaymentAmount(amount string, native bool) (*big.Int, error) {
+	// The units of ether do not apply to tokens, so token amounts take none
+	var value *big.Int
+	var err error
+	if native {
+		value, err = utils.ParseAmount(amount, "ether")
+	} else {
+		value, err = utils.ParseTokenAmount(amount)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if value.Sign() <= 0 {
+		return nil, fmt.Errorf("amount must be positive, got %q", amount)
+	}
+	return value, nil
+}
+
+// PaymentTotals sums the amounts of the payments per asset.
+func PaymentTotals(payments []Payment) map[string]*big.Int {
+	totals := make(map[string]*big.Int)
+	for _, payment := range payments {
+		if totals[payment.Asset] == nil {
+			totals[payment.Asset] = new(big.Int)
+		}
+		totals[payment.Asset].Add(totals[payment.Asset], payment.Amount)
+	}
+	return totals
+}
+
+// ReadPaymentResults loads a results file written by WritePaymentResults,
+// keyed by row. A missing file yields no results.
+func ReadPaymentResults(path string) (map[int]PaymentResult, error) {
+	results := make(map[int]PaymentResult)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return results, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results: %v", err)
+	}
+	defer file.Close()
+
+	records, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read results: %v", err)
+	}
+
+	for i, record := range records {
+		if i == 0 {
+			continue
+		}
+		if len(record) != len(paymentResultsHeader) {
+			return nil, fmt.Errorf("results line %d: expected %d columns", i+1, len(paymentResultsHeader))
+		}
+
+		row, err := strconv.Atoi(record[0])
+		if err != nil {
+			return nil, fmt.Errorf("results line %d: invalid row %q", i+1, record[0])
+		}
+		amount, ok := new(big.Int).SetString(record[2], 10)
+		if !ok {
+			return nil, fmt.Errorf("results line %d: invalid amount %q", i+1, record[2])
+		}
+
+		result := PaymentResult{
+			Payment: Payment{Row: row, Address: record[1], Amount: amount, Asset: record[3]},
+			Hash:    record[5],
+			Status:  record[6],
+			Error:   record[7],
+		}
+		if record[4] != "" {
+			nonce, err := strconv.ParseUint(record[4], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("results line %d: invalid nonce %q", i+1, record[4])
+			}
+			result.Nonce = &nonce
+		}
+		results[row] = result
+	}
+
+	return results, nil
+}
+
+// WritePaymentResults atomically replaces the results file, so a crash never
+// leaves it half written.
+func WritePaymentResults(path string, results []PaymentResult) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to create results: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := csv.NewWriter(tmp)
+	writer.Write(paymentResultsHeader)
+	for _, result := range results {
+		nonce := ""
+		if result.Nonce != nil {
+			nonce = strconv.FormatUint(*result.Nonce, 10)
+		}
+		writer.Write([]string{
+			strconv.Itoa(result.Row),
+			result.Address,
+			result.Amount.String(),
+			result.Asset,
+			nonce,
+			result.Hash,
+			result.Status,
+			result.Error,
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write results: %v", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write results: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write results: %v", err)
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,111 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const batchToken = "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+
+func TestParsePayments(t *testing.T) {
+	csv := `address,amount,asset
+0x70997970C51812dc3A010C7d01b50e0d17dc79C8,1.5,ETH
+0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC,250,` + batchToken + `
+0x90F79bf6EB2c4f870365E785982E1f101E93b906,2
+`
+	payments, err := ParsePayments(strings.NewReader(csv), "ETH")
+	require.NoError(t, err)
+	require.Len(t, payments, 3)
+
+	require.Equal(t, 2, payments[0].Row)
+	require.True(t, payments[0].IsNative())
+	require.Equal(t, "1500000000000000000", payments[0].Amount.String())
+
+	require.False(t, payments[1].IsNative())
+	require.Equal(t, batchToken, payments[1].Asset)
+	require.Equal(t, big.NewInt(250), payments[1].Amount)
+
+	require.True(t, payments[2].IsNative())
+
+	totals := PaymentTotals(payments)
+	require.Equal(t, "3500000000000000000", totals[""].String())
+	require.Equal(t, big.NewInt(250), totals[batchToken])
+}
+
+func TestParsePaymentsReportsEveryInvalidRow(t *testing.T) {
+	csv := `0x70997970C51812dc3A010C7d01b50e0d17dc79C8,1
+notanaddress,1
+0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC,-1
+0x90F79bf6EB2c4f870365E785982E1f101E93b906,1.5,` + batchToken + `
+0x90F79bf6EB2c4f870365E785982E1f101E93b906,1ether,` + batchToken + `
+`
+	_, err := ParsePayments(strings.NewReader(csv), "ETH")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "row 2")
+	require.Contains(t, err.Error(), "row 3")
+	require.Contains(t, err.Error(), "row 4")
+	require.Contains(t, err.Error(), "row 5")
+	require.NotContains(t, err.Error(), "row 1")
+}
+
+func TestPaymentResultsRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "payments.results.csv")
+
+	results, err := ReadPaymentResults(path)
+	require.NoError(t, err)
+	require.Empty(t, results)
+
+	nonce := uint64(7)
+	written := []PaymentResult{
+		{
+			Payment: Payment{Row: 1, Address: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8", Amount: big.NewInt(10)},
+			Nonce:   &nonce,
+			Hash:    "0xabc",
+			Status:  PaymentConfirmed,
+		},
+		{
+			Payment: Payment{Row: 2, Address: "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC", Amount: big.NewInt(20), Asset: batchToken},
+		},
+	}
+	require.NoError(t, WritePaymentResults(path, written))
+
+	results, err = ReadPaymentResults(path)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	require.Equal(t, PaymentConfirmed, results[1].Status)
+	require.Equal(t, uint64(7), *results[1].Nonce)
+	require.Equal(t, "0xabc", results[1].Hash)
+	require.Nil(t, results[2].Nonce)
+	require.Equal(t, batchToken, results[2].Asset)
+	require.Equal(t, big.NewInt(20), results[2].Amount)
+}
+
+func TestSenderWaitForReceiptNonceUsed(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	// The account has mined nonces 0 to 4 and the transaction is unknown
+	server := newRPCServer(t, func(method string) string {
+		switch method {
+		case "eth_chainId":
+			return `"0x1"`
+		case "eth_getTransactionCount":
+			return `"0x5"`
+		case "eth_getTransactionReceipt":
+			return `null`
+		}
+		return ""
+	})
+	sender, err := NewSender(hex.EncodeToString(crypto.FromECDSA(key)), Network{ChainId: 1, RpcUrl: server.URL})
+	require.NoError(t, err)
+	defer sender.Close()
+
+	_, err = sender.WaitForReceipt("0x0000000000000000000000000000000000000000000000000000000000000001", 3)
+	require.ErrorIs(t, err, ErrNonceUsed)
+}
@@ -0,0 +1,584 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/boltdb/bolt"
+)
+
+var (
+	accountsBucket = []byte("accounts")
+	networksBucket = []byte("networks")
+	selectedBucket = []byte("selected")
+
+	archivedAccountsBucket = []byte("archived_accounts")
+	archivedNetworksBucket = []byte("archived_networks")
+
+	selectedAccountKey = []byte("acc")
+	selectedNetworkKey = []byte("network")
+)
+
+// BoltStore is a Store backed by a BoltDB database. Accounts and networks are
+// stored as JSON keyed by label, and the selected labels in the selected
+// bucket.
+type BoltStore struct {
+	db *bolt.DB
+	// tx is set on the store Transaction passes to its callback, whose
+	// methods all run in that transaction
+	tx *bolt.Tx
+}
+
+func NewBoltStore(db *bolt.DB) *BoltStore {
+	return &BoltStore{db: db}
+}
+
+// OpenBoltStore opens, or creates, the database at path and migrates it to
+// the current schema version.
+func OpenBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %v", err)
+	}
+
+	_, err = Migrate(db, false)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	}
+	return NewBoltStore(db), nil
+}
+
+func (s *BoltStore) Close() error {
+	if s.tx != nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+func (s *BoltStore) view(fn func(tx *bolt.Tx) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+	return s.db.View(fn)
+}
+
+func (s *BoltStore) update(fn func(tx *bolt.Tx) error) error {
+	if s.tx != nil {
+		return fn(s.tx)
+	}
+	return s.db.Update(fn)
+}
+
+func (s *BoltStore) Transaction(update func(store Store) error) error {
+	if s.tx != nil {
+		return update(s)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return update(&BoltStore{db: s.db, tx: tx})
+	})
+}
+
+// selectedLabel returns the label stored under key in the selected bucket.
+func selectedLabel(tx *bolt.Tx, key []byte) string {
+	bucket := tx.Bucket(selectedBucket)
+	if bucket == nil {
+		return ""
+	}
+	return string(bucket.Get(key))
+}
+
+func setSelectedLabel(tx *bolt.Tx, key []byte, label string) error {
+	bucket, err := tx.CreateBucketIfNotExists(selectedBucket)
+	if err != nil {
+		return fmt.Errorf("create bucket: %s", err)
+	}
+	if label == "" {
+		return bucket.Delete(key)
+	}
+	return bucket.Put(key, []byte(label))
+}
+
+// remove deletes label from bucket and, if it was selected, selects the
+// first remaining key or clears the selection.
+func remove(tx *bolt.Tx, name []byte, selectedKey []byte, label string, notFound error) error {
+	bucket := tx.Bucket(name)
+	if bucket == nil || bucket.Get([]byte(label)) == nil {
+		return fmt.Errorf("%w: %s", notFound, label)
+	}
+	err := bucket.Delete([]byte(label))
+	if err != nil {
+		return err
+	}
+	return reselect(tx, bucket, selectedKey, label)
+}
+
+// reselect selects the first key of bucket, or clears the selection, if
+// label was selected.
+func reselect(tx *bolt.Tx, bucket *bolt.Bucket, selectedKey []byte, label string) error {
+	if selectedLabel(tx, selectedKey) != label {
+		return nil
+	}
+	next, _ := bucket.Cursor().First()
+	return setSelectedLabel(tx, selectedKey, string(next))
+}
+
+// move moves the record label from one bucket to another, failing with
+// exists if the destination has the label already.
+func move(tx *bolt.Tx, from, to []byte, label string, notFound, exists error) error {
+	source := tx.Bucket(from)
+	if source == nil || source.Get([]byte(label)) == nil {
+		return fmt.Errorf("%w: %s", notFound, label)
+	}
+	destination, err := tx.CreateBucketIfNotExists(to)
+	if err != nil {
+		return fmt.Errorf("create bucket: %s", err)
+	}
+	if destination.Get([]byte(label)) != nil {
+		return fmt.Errorf("%w: %s", exists, label)
+	}
+
+	// Values returned by Get are only valid until the bucket changes
+	value := append([]byte{}, source.Get([]byte(label))...)
+	err = destination.Put([]byte(label), value)
+	if err != nil {
+		return err
+	}
+	return source.Delete([]byte(label))
+}
+
+// archive moves label to an archive bucket, reselecting like remove.
+func archive(tx *bolt.Tx, name, archiveName []byte, selectedKey []byte, label string, notFound, exists error) error {
+	err := move(tx, name, archiveName, label, notFound, exists)
+	if err != nil {
+		return err
+	}
+	return reselect(tx, tx.Bucket(name), selectedKey, label)
+}
+
+// putArchived writes a record to an archive bucket.
+func putArchived(tx *bolt.Tx, name []byte, label string, value interface{}) error {
+	bucket, err := tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return fmt.Errorf("create bucket: %s", err)
+	}
+
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+
+	return bucket.Put([]byte(label), valueJSON)
+}
+
+// listArchived calls decode with every record of an archive bucket.
+func listArchived(tx *bolt.Tx, name []byte, decode func(v []byte) error) error {
+	bucket := tx.Bucket(name)
+	if bucket == nil {
+		return nil
+	}
+	return bucket.ForEach(func(k, v []byte) error {
+		return decode(v)
+	})
+}
+
+func getAccount(tx *bolt.Tx, label string) (Account, error) {
+	var account Account
+
+	bucket := tx.Bucket(accountsBucket)
+	if bucket == nil {
+		return account, fmt.Errorf("%w: %s", ErrAccountNotFound, label)
+	}
+
+	accountJSON := bucket.Get([]byte(label))
+	if accountJSON == nil {
+		return account, fmt.Errorf("%w: %s", ErrAccountNotFound, label)
+	}
+
+	err := json.Unmarshal(accountJSON, &account)
+	if err != nil {
+		return account, fmt.Errorf("json unmarshal: %s", err)
+	}
+
+	account.Selected = selectedLabel(tx, selectedAccountKey) == label
+	return account, nil
+}
+
+func putAccount(tx *bolt.Tx, account Account) error {
+	bucket, err := tx.CreateBucketIfNotExists(accountsBucket)
+	if err != nil {
+		return fmt.Errorf("create bucket: %s", err)
+	}
+
+	accountJSON, err := json.Marshal(account)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+
+	return bucket.Put([]byte(account.Label), accountJSON)
+}
+
+func (s *BoltStore) GetAccount(label string) (Account, error) {
+	var account Account
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		account, err = getAccount(tx, label)
+		return err
+	})
+	return account, err
+}
+
+func (s *BoltStore) ListAccounts() ([]Account, error) {
+	var accounts []Account
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(accountsBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		selected := selectedLabel(tx, selectedAccountKey)
+		return bucket.ForEach(func(k, v []byte) error {
+			var account Account
+			err := json.Unmarshal(v, &account)
+			if err != nil {
+				return fmt.Errorf("json unmarshal: %s", err)
+			}
+
+			account.Selected = account.Label == selected
+			accounts = append(accounts, account)
+			return nil
+		})
+	})
+	return accounts, err
+}
+
+func (s *BoltStore) ImportAccount(account Account) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(accountsBucket)
+		if bucket != nil && bucket.Get([]byte(account.Label)) != nil {
+			return fmt.Errorf("%w: %s", ErrAccountExists, account.Label)
+		}
+
+		err := putAccount(tx, account)
+		if err != nil {
+			return err
+		}
+
+		return setSelectedLabel(tx, selectedAccountKey, account.Label)
+	})
+}
+
+func (s *BoltStore) UpdateAccount(label string, update func(*Account) error) error {
+	return s.update(func(tx *bolt.Tx) error {
+		account, err := getAccount(tx, label)
+		if err != nil {
+			return err
+		}
+
+		err = update(&account)
+		if err != nil {
+			return err
+		}
+		if account.Label != label {
+			return fmt.Errorf("account %s cannot be renamed to %s", label, account.Label)
+		}
+
+		return putAccount(tx, account)
+	})
+}
+
+func (s *BoltStore) RemoveAccount(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return remove(tx, accountsBucket, selectedAccountKey, label, ErrAccountNotFound)
+	})
+}
+
+func (s *BoltStore) ArchiveAccount(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return archive(tx, accountsBucket, archivedAccountsBucket, selectedAccountKey, label, ErrAccountNotFound, ErrAccountExists)
+	})
+}
+
+func (s *BoltStore) UnarchiveAccount(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return move(tx, archivedAccountsBucket, accountsBucket, label, ErrAccountNotFound, ErrAccountExists)
+	})
+}
+
+func (s *BoltStore) PutArchivedAccount(account Account) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return putArchived(tx, archivedAccountsBucket, account.Label, account)
+	})
+}
+
+func (s *BoltStore) ListArchivedAccounts() ([]Account, error) {
+	var accounts []Account
+	err := s.view(func(tx *bolt.Tx) error {
+		return listArchived(tx, archivedAccountsBucket, func(v []byte) error {
+			var account Account
+			err := json.Unmarshal(v, &account)
+			if err != nil {
+				return fmt.Errorf("json unmarshal: %s", err)
+			}
+			accounts = append(accounts, account)
+			return nil
+		})
+	})
+	return accounts, err
+}
+
+func (s *BoltStore) SelectAccount(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		_, err := getAccount(tx, label)
+		if err != nil {
+			return err
+		}
+		return setSelectedLabel(tx, selectedAccountKey, label)
+	})
+}
+
+func (s *BoltStore) GetSelectedAccount() (Account, error) {
+	var account Account
+	err := s.view(func(tx *bolt.Tx) error {
+		label := selectedLabel(tx, selectedAccountKey)
+		if label == "" {
+			return ErrAccountNotSelected
+		}
+
+		var err error
+		account, err = getAccount(tx, label)
+		if errors.Is(err, ErrAccountNotFound) {
+			// Never left behind by this store, but older versions did
+			return ErrAccountNotSelected
+		}
+		return err
+	})
+	return account, err
+}
+
+func getNetwork(tx *bolt.Tx, label string) (Network, error) {
+	var network Network
+
+	bucket := tx.Bucket(networksBucket)
+	if bucket == nil {
+		return network, fmt.Errorf("%w: %s", ErrNetworkNotFound, label)
+	}
+
+	networkJSON := bucket.Get([]byte(label))
+	if networkJSON == nil {
+		return network, fmt.Errorf("%w: %s", ErrNetworkNotFound, label)
+	}
+
+	err := json.Unmarshal(networkJSON, &network)
+	if err != nil {
+		return network, fmt.Errorf("json unmarshal: %s", err)
+	}
+
+	network.Selected = selectedLabel(tx, selectedNetworkKey) == label
+	return network, nil
+}
+
+func putNetwork(tx *bolt.Tx, network Network) error {
+	bucket, err := tx.CreateBucketIfNotExists(networksBucket)
+	if err != nil {
+		return fmt.Errorf("create bucket: %s", err)
+	}
+
+	networkJSON, err := json.Marshal(network)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+
+	return bucket.Put([]byte(network.Label), networkJSON)
+}
+
+func (s *BoltStore) GetNetwork(label string) (Network, error) {
+	var network Network
+	err := s.view(func(tx *bolt.Tx) error {
+		var err error
+		network, err = getNetwork(tx, label)
+		return err
+	})
+	return network, err
+}
+
+func (s *BoltStore) ListNetworks() ([]Network, error) {
+	var networks []Network
+	err := s.view(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(networksBucket)
+		if bucket == nil {
+			return nil
+		}
+
+		selected := selectedLabel(tx, selectedNetworkKey)
+		return bucket.ForEach(func(k, v []byte) error {
+			var network Network
+			err := json.Unmarshal(v, &network)
+			if err != nil {
+				return fmt.Errorf("json unmarshal: %s", err)
+			}
+
+			network.Selected = network.Label == selected
+			networks = append(networks, network)
+			return nil
+		})
+	})
+	return networks, err
+}
+
+func (s *BoltStore) AddNetwork(network Network) error {
+	return s.update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(networksBucket)
+		if bucket != nil && bucket.Get([]byte(network.Label)) != nil {
+			return fmt.Errorf("%w: %s", ErrNetworkExists, network.Label)
+		}
+
+		err := putNetwork(tx, network)
+		if err != nil {
+			return err
+		}
+
+		return setSelectedLabel(tx, selectedNetworkKey, network.Label)
+	})
+}
+
+func (s *BoltStore) UpdateNetwork(label string, update func(*Network) error) error {
+	return s.update(func(tx *bolt.Tx) error {
+		network, err := getNetwork(tx, label)
+		if err != nil {
+			return err
+		}
+
+		err = update(&network)
+		if err != nil {
+			return err
+		}
+		if network.Label != label {
+			return renameNetwork(tx, label, network)
+		}
+
+		return putNetwork(tx, network)
+	})
+}
+
+// renameNetwork stores network under its new label in place of label, moving
+// the selection and the tracked contracts of every account with it.
+func renameNetwork(tx *bolt.Tx, label string, network Network) error {
+	_, err := getNetwork(tx, network.Label)
+	if err == nil {
+		return fmt.Errorf("%w: %s", ErrNetworkExists, network.Label)
+	}
+
+	err = tx.Bucket(networksBucket).Delete([]byte(label))
+	if err != nil {
+		return err
+	}
+	err = putNetwork(tx, network)
+	if err != nil {
+		return err
+	}
+	if selectedLabel(tx, selectedNetworkKey) == label {
+		err = setSelectedLabel(tx, selectedNetworkKey, network.Label)
+		if err != nil {
+			return err
+		}
+	}
+
+	bucket := tx.Bucket(accountsBucket)
+	if bucket == nil {
+		return nil
+	}
+	var accounts []Account
+	err = bucket.ForEach(func(k, v []byte) error {
+		var account Account
+		err := json.Unmarshal(v, &account)
+		if err != nil {
+			return fmt.Errorf("json unmarshal: %s", err)
+		}
+		if renameMultiTokens(&account, label, network.Label) {
+			accounts = append(accounts, account)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	// Buckets must not be modified while iterating over them
+	for _, account := range accounts {
+		err = putAccount(tx, account)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *BoltStore) DeleteNetwork(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return remove(tx, networksBucket, selectedNetworkKey, label, ErrNetworkNotFound)
+	})
+}
+
+func (s *BoltStore) ArchiveNetwork(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return archive(tx, networksBucket, archivedNetworksBucket, selectedNetworkKey, label, ErrNetworkNotFound, ErrNetworkExists)
+	})
+}
+
+func (s *BoltStore) UnarchiveNetwork(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return move(tx, archivedNetworksBucket, networksBucket, label, ErrNetworkNotFound, ErrNetworkExists)
+	})
+}
+
+func (s *BoltStore) PutArchivedNetwork(network Network) error {
+	return s.update(func(tx *bolt.Tx) error {
+		return putArchived(tx, archivedNetworksBucket, network.Label, network)
+	})
+}
+
+func (s *BoltStore) ListArchivedNetworks() ([]Network, error) {
+	var networks []Network
+	err := s.view(func(tx *bolt.Tx) error {
+		return listArchived(tx, archivedNetworksBucket, func(v []byte) error {
+			var network Network
+			err := json.Unmarshal(v, &network)
+			if err != nil {
+				return fmt.Errorf("json unmarshal: %s", err)
+			}
+			networks = append(networks, network)
+			return nil
+		})
+	})
+	return networks, err
+}
+
+func (s *BoltStore) SelectNetwork(label string) error {
+	return s.update(func(tx *bolt.Tx) error {
+		_, err := getNetwork(tx, label)
+		if err != nil {
+			return err
+		}
+		return setSelectedLabel(tx, selectedNetworkKey, label)
+	})
+}
+
+func (s *BoltStore) GetSelectedNetwork() (Network, error) {
+	var network Network
+	err := s.view(func(tx *bolt.Tx) error {
+		label := selectedLabel(tx, selectedNetworkKey)
+		if label == "" {
+			return ErrNetworkNotSelected
+		}
+
+		var err error
+		network, err = getNetwork(tx, label)
+		if errors.Is(err, ErrNetworkNotFound) {
+			// Never left behind by this store, but older versions did
+			return ErrNetworkNotSelected
+		}
+		return err
+	})
+	return network, err
+}
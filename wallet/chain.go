@@ -0,0 +1,52 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// GetChainID asks the node behind an endpoint of network for its chain id
+// with eth_chainId.
+func GetChainID(endpoint string, network Network) (int, error) {
+	client, err := dial(endpoint, network)
+	if err != nil {
+		return 0, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("failed to get chain id: %v", err)
+	}
+	return int(chainID.Int64()), nil
+}
+
+// verifyChainID fails with ErrChainIDMismatch unless the node reports the
+// chain id stored for the network, so that nothing is ever signed for a
+// chain other than the one the user expects.
+func verifyChainID(ctx context.Context, client *ethclient.Client, network Network) error {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get chain id: %v", err)
+	}
+	if chainID.Cmp(big.NewInt(int64(network.ChainId))) != 0 {
+		return fmt.Errorf("%w: network %s has chain id %d but the RPC endpoint reports %s", ErrChainIDMismatch, network.Label, network.ChainId, chainID)
+	}
+	return nil
+}
+
+// signTx signs tx for the chain of the network. Clients are dialed with
+// dialNetwork, which has verified the chain id of the node already.
+func signTx(tx *types.Transaction, privateKey *ecdsa.PrivateKey, network Network) (*types.Transaction, error) {
+	chainID := big.NewInt(int64(network.ChainId))
+	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign transaction: %v", err)
+	}
+	return signedTx, nil
+}
@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/require"
+)
+
+// newRPCServer answers JSON-RPC calls over HTTP with the result returned by
+// results for the method, or a method not found error when it returns "".
+func newRPCServer(t *testing.T, results func(method string) string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+		result := results(request.Method)
+		if result == "" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, request.ID)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID, result)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// newChainIDServer answers eth_chainId with chainID.
+func newChainIDServer(t *testing.T, chainID int) *httptest.Server {
+	return newRPCServer(t, func(method string) string {
+		if method == "eth_chainId" {
+			return fmt.Sprintf(`"0x%x"`, chainID)
+		}
+		return ""
+	})
+}
+
+func TestGetChainID(t *testing.T) {
+	server := newChainIDServer(t, 1337)
+
+	chainID, err := GetChainID(server.URL, Network{})
+	require.NoError(t, err)
+	require.Equal(t, 1337, chainID)
+}
+
+func TestVerifyChainID(t *testing.T) {
+	server := newChainIDServer(t, 1)
+	client, err := ethclient.Dial(server.URL)
+	require.NoError(t, err)
+	defer client.Close()
+
+	require.NoError(t, verifyChainID(context.Background(), client, Network{Label: "mainnet", ChainId: 1, RpcUrl: server.URL}))
+
+	err = verifyChainID(context.Background(), client, Network{Label: "sepolia", ChainId: 11155111, RpcUrl: server.URL})
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+}
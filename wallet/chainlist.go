@@ -0,0 +1,121 @@
+package wallet
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// chainlistEntry is a network in the format of chainlist.org and
+// ethereum-lists/chains. Entries of rpc are URLs, or objects with a url in
+// the format of chainlist.org's rpcs.json.
+type chainlistEntry struct {
+	Name           string                  `json:"name"`
+	ChainId        int                     `json:"chainId"`
+	NativeCurrency struct{ Symbol string } `json:"nativeCurrency"`
+	Rpc            []json.RawMessage       `json:"rpc"`
+	Explorers      []struct {
+		Url      string `json:"url"`
+		Standard string `json:"standard"`
+	} `json:"explorers"`
+}
+
+var labelSeparators = regexp.MustCompile(`[^a-z0-9]+`)
+
+// chainlistLabel turns a chain name such as "OP Mainnet" into "op-mainnet".
+func chainlistLabel(name string) string {
+	return strings.Trim(labelSeparators.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// network converts the entry, keeping the endpoints that need no API key,
+// and validates it like AddNetwork.
+func (e chainlistEntry) network() (Network, error) {
+	network := Network{
+		Label:   chainlistLabel(e.Name),
+		ChainId: e.ChainId,
+		Symbol:  e.NativeCurrency.Symbol,
+	}
+	if network.ChainId <= 0 {
+		return Network{}, fmt.Errorf("chain %q has no chain id", e.Name)
+	}
+	if network.Symbol == "" {
+		return Network{}, fmt.Errorf("chain %q has no native currency symbol", e.Name)
+	}
+
+	for _, raw := range e.Rpc {
+		var url string
+		if json.Unmarshal(raw, &url) != nil {
+			var rpc struct{ Url string }
+			if err := json.Unmarshal(raw, &rpc); err != nil {
+				return Network{}, fmt.Errorf("chain %q has an invalid rpc entry: %s", e.Name, raw)
+			}
+			url = rpc.Url
+		}
+		// Templates such as ${INFURA_API_KEY} need a key we do not have
+		if url == "" || strings.Contains(url, "${") || ValidateRpcUrl(url) != nil {
+			continue
+		}
+		if network.RpcUrl == "" {
+			network.RpcUrl = url
+		} else {
+			network.RpcUrls = append(network.RpcUrls, url)
+		}
+	}
+	if network.RpcUrl == "" {
+		return Network{}, fmt.Errorf("chain %q has no public RPC endpoint", e.Name)
+	}
+
+	for _, explorer := range e.Explorers {
+		if explorer.Standard == "EIP3091" || network.BlockExplorerUrl == "" {
+			network.BlockExplorerUrl = explorer.Url
+		}
+		if explorer.Standard == "EIP3091" {
+			break
+		}
+	}
+
+	return network, validateNetwork(network)
+}
+
+// ParseChainlist reads a chainlist-style JSON file, either a single chain or
+// an array of chains. If chainId is not zero only that chain is returned.
+func ParseChainlist(r io.Reader, chainId int) ([]Network, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []chainlistEntry
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var entry chainlistEntry
+		err = json.Unmarshal(trimmed, &entry)
+		entries = append(entries, entry)
+	} else {
+		err = json.Unmarshal(data, &entries)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse chainlist: %v", err)
+	}
+
+	var networks []Network
+	for _, entry := range entries {
+		if chainId != 0 && entry.ChainId != chainId {
+			continue
+		}
+		network, err := entry.network()
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	if len(networks) == 0 {
+		if chainId != 0 {
+			return nil, fmt.Errorf("%w: no chain with id %d in chainlist", ErrNetworkNotFound, chainId)
+		}
+		return nil, fmt.Errorf("chainlist has no chains")
+	}
+	return networks, nil
+}
@@ -0,0 +1,71 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testChainlist = `[
+	{
+		"name": "OP Mainnet",
+		"chainId": 10,
+		"nativeCurrency": {"name": "Ether", "symbol": "ETH", "decimals": 18},
+		"rpc": [
+			"https://optimism-mainnet.infura.io/v3/${INFURA_API_KEY}",
+			"https://mainnet.optimism.io",
+			{"url": "wss://optimism.example.org", "tracking": "none"}
+		],
+		"explorers": [
+			{"name": "blockscout", "url": "https://optimism.blockscout.com", "standard": "none"},
+			{"name": "etherscan", "url": "https://optimistic.etherscan.io", "standard": "EIP3091"}
+		]
+	},
+	{"name": "Broken", "chainId": 5, "rpc": []}
+]`
+
+func TestParseChainlist(t *testing.T) {
+	networks, err := ParseChainlist(strings.NewReader(testChainlist), 10)
+	require.NoError(t, err)
+	require.Len(t, networks, 1)
+
+	network := networks[0]
+	require.Equal(t, "op-mainnet", network.Label)
+	require.Equal(t, 10, network.ChainId)
+	require.Equal(t, "ETH", network.Symbol)
+	require.Equal(t, "https://mainnet.optimism.io", network.RpcUrl)
+	require.Equal(t, []string{"wss://optimism.example.org"}, network.RpcUrls)
+	require.Equal(t, "https://optimistic.etherscan.io", network.BlockExplorerUrl)
+
+	// Chains are validated like AddNetwork
+	_, err = ParseChainlist(strings.NewReader(testChainlist), 0)
+	require.Error(t, err)
+
+	_, err = ParseChainlist(strings.NewReader(testChainlist), 1)
+	require.ErrorIs(t, err, ErrNetworkNotFound)
+
+	// A file can also hold a single chain
+	networks, err = ParseChainlist(strings.NewReader(`{"name": "Gnosis", "chainId": 100, "nativeCurrency": {"symbol": "XDAI"}, "rpc": ["https://rpc.gnosischain.com"]}`), 0)
+	require.NoError(t, err)
+	require.Equal(t, "gnosis", networks[0].Label)
+}
+
+func TestPresets(t *testing.T) {
+	for _, network := range Presets() {
+		require.NoError(t, validateNetwork(network), network.Label)
+		require.NotZero(t, network.ChainId, network.Label)
+	}
+
+	sepolia, ok := Preset("sepolia")
+	require.True(t, ok)
+	require.Equal(t, 11155111, sepolia.ChainId)
+
+	// Presets cannot be changed through the values returned
+	sepolia.RpcUrls[0] = "changed"
+	sepolia, _ = Preset("sepolia")
+	require.NotEqual(t, "changed", sepolia.RpcUrls[0])
+
+	_, ok = Preset("missing")
+	require.False(t, ok)
+}
@@ -0,0 +1,188 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// probeTimeout bounds the time an endpoint may take to answer a probe.
+var probeTimeout = 5 * time.Second
+
+// Endpoints returns the RPC endpoints of the network in order of priority:
+// RpcUrl followed by the fallbacks in RpcUrls.
+func (n Network) Endpoints() []string {
+	endpoints := []string{}
+	seen := make(map[string]bool)
+	for _, endpoint := range append([]string{n.RpcUrl}, n.RpcUrls...) {
+		if endpoint == "" || seen[endpoint] {
+			continue
+		}
+		seen[endpoint] = true
+		endpoints = append(endpoints, endpoint)
+	}
+	return endpoints
+}
+
+// dialEndpoint connects to endpoint and checks that it answers with the chain
+// id of the network.
+func dialEndpoint(endpoint string, network Network) (*ethclient.Client, error) {
+	client, err := dial(endpoint, network)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+	err = verifyChainID(ctx, client, network)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// dialNetwork connects to the first endpoint of the network that is
+// reachable and on the right chain.
+func dialNetwork(network Network) (*ethclient.Client, error) {
+	var errs []error
+	for _, endpoint := range network.Endpoints() {
+		client, err := dialEndpoint(endpoint, network)
+		if err == nil {
+			return client, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", RedactUrl(endpoint), err))
+	}
+	return nil, fmt.Errorf("failed to connect to the Ethereum client: %w", errors.Join(errs...))
+}
+
+// isNodeError reports whether err was returned by a node that answered, as
+// opposed to a transport failure that another endpoint may not have.
+func isNodeError(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) || errors.Is(err, ethereum.NotFound)
+}
+
+// withEndpoints calls query with a client for each endpoint of the network in
+// turn, until one succeeds or the node itself rejects the query.
+func withEndpoints(network Network, query func(client *ethclient.Client) error) error {
+	var errs []error
+	for _, endpoint := range network.Endpoints() {
+		client, err := dialEndpoint(endpoint, network)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", RedactUrl(endpoint), err))
+			continue
+		}
+		err = query(client)
+		client.Close()
+		if err == nil || isNodeError(err) {
+			return err
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", RedactUrl(endpoint), err))
+	}
+	return errors.Join(errs...)
+}
+
+// sendTransaction broadcasts a signed transaction with client and, if the
+// endpoint cannot be reached, with the other endpoints of the network. The
+// transaction is signed already, so sending it again cannot duplicate it.
+func sendTransaction(client *ethclient.Client, network Network, tx *types.Transaction) error {
+	err := client.SendTransaction(context.Background(), tx)
+	if err != nil && !isNodeError(err) {
+		err = withEndpoints(network, func(client *ethclient.Client) error {
+			return client.SendTransaction(context.Background(), tx)
+		})
+	}
+	if err != nil {
+		return nodeError(err)
+	}
+	return nil
+}
+
+// EndpointHealth is the result of probing one RPC endpoint.
+type EndpointHealth struct {
+	Url         string
+	Priority    int
+	ChainId     int
+	BlockNumber uint64
+	Latency     time.Duration
+	// Lag is the number of blocks behind the most advanced endpoint
+	Lag     uint64
+	Healthy bool
+	Error   string
+}
+
+// CheckEndpoints probes every endpoint of the network concurrently for its
+// chain id and latest block number. An endpoint is healthy when it answers
+// with the chain id of the network.
+func CheckEndpoints(network Network) []EndpointHealth {
+	endpoints := network.Endpoints()
+	results := make([]EndpointHealth, len(endpoints))
+
+	var wg sync.WaitGroup
+	for i, endpoint := range endpoints {
+		wg.Add(1)
+		go func(i int, endpoint string) {
+			defer wg.Done()
+			results[i] = probeEndpoint(endpoint, network)
+			results[i].Priority = i + 1
+		}(i, endpoint)
+	}
+	wg.Wait()
+
+	var head uint64
+	for _, result := range results {
+		if result.Healthy && result.BlockNumber > head {
+			head = result.BlockNumber
+		}
+	}
+	for i := range results {
+		if results[i].Healthy {
+			results[i].Lag = head - results[i].BlockNumber
+		}
+	}
+	return results
+}
+
+func probeEndpoint(endpoint string, network Network) EndpointHealth {
+	health := EndpointHealth{Url: endpoint}
+
+	client, err := dial(endpoint, network)
+	if err != nil {
+		health.Error = err.Error()
+		return health
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		health.Error = fmt.Sprintf("failed to get chain id: %v", err)
+		return health
+	}
+	health.ChainId = int(chainID.Int64())
+
+	start := time.Now()
+	health.BlockNumber, err = client.BlockNumber(ctx)
+	health.Latency = time.Since(start)
+	if err != nil {
+		health.Error = fmt.Sprintf("failed to get block number: %v", err)
+		return health
+	}
+
+	if health.ChainId != network.ChainId {
+		health.Error = fmt.Sprintf("chain id %d does not match %d", health.ChainId, network.ChainId)
+		return health
+	}
+	health.Healthy = true
+	return health
+}
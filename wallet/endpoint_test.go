@@ -0,0 +1,67 @@
+package wallet
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// newNodeServer answers eth_chainId with 0x1 and eth_blockNumber and
+// eth_getBalance with the given values.
+func newNodeServer(t *testing.T, blockNumber, balance string) *httptest.Server {
+	return newRPCServer(t, func(method string) string {
+		switch method {
+		case "eth_chainId":
+			return `"0x1"`
+		case "eth_blockNumber":
+			return `"` + blockNumber + `"`
+		case "eth_getBalance":
+			return `"` + balance + `"`
+		}
+		return ""
+	})
+}
+
+func TestEndpoints(t *testing.T) {
+	network := Network{RpcUrl: "http://a", RpcUrls: []string{"http://b", "http://a", ""}}
+	require.Equal(t, []string{"http://a", "http://b"}, network.Endpoints())
+}
+
+func TestGetBalanceFailover(t *testing.T) {
+	down := httptest.NewServer(nil)
+	down.Close()
+	wrongChain := newChainIDServer(t, 5)
+	up := newNodeServer(t, "0x10", "0x2a")
+
+	network := Network{Label: "mainnet", ChainId: 1, RpcUrl: down.URL, RpcUrls: []string{wrongChain.URL, up.URL}}
+	balance, err := GetBalance("0x0000000000000000000000000000000000000001", network)
+	require.NoError(t, err)
+	require.Equal(t, "42", balance.String())
+
+	// Without a working endpoint every failure is reported
+	network.RpcUrls = []string{wrongChain.URL}
+	_, err = GetBalance("0x0000000000000000000000000000000000000001", network)
+	require.ErrorIs(t, err, ErrChainIDMismatch)
+}
+
+func TestCheckEndpoints(t *testing.T) {
+	down := httptest.NewServer(nil)
+	down.Close()
+	behind := newNodeServer(t, "0x10", "0x0")
+	ahead := newNodeServer(t, "0x12", "0x0")
+
+	results := CheckEndpoints(Network{ChainId: 1, RpcUrl: behind.URL, RpcUrls: []string{down.URL, ahead.URL}})
+	require.Len(t, results, 3)
+
+	require.True(t, results[0].Healthy)
+	require.Equal(t, 1, results[0].Priority)
+	require.Equal(t, uint64(16), results[0].BlockNumber)
+	require.Equal(t, uint64(2), results[0].Lag)
+
+	require.False(t, results[1].Healthy)
+	require.NotEmpty(t, results[1].Error)
+
+	require.True(t, results[2].Healthy)
+	require.Equal(t, uint64(0), results[2].Lag)
+}
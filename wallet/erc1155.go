@@ -0,0 +1,159 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ERC-1155 ABI for the balance and transfer functions
+const erc1155ABI = `[
+	{"constant":true,"inputs":[{"name":"account","type":"address"},{"name":"id","type":"uint256"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"accounts","type":"address[]"},{"name":"ids","type":"uint256[]"}],"name":"balanceOfBatch","outputs":[{"name":"","type":"uint256[]"}],"stateMutability":"view","type":"function"},
+	{"constant":false,"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"id","type":"uint256"},{"name":"amount","type":"uint256"},{"name":"data","type":"bytes"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"constant":false,"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"ids","type":"uint256[]"},{"name":"amounts","type":"uint256[]"},{"name":"data","type":"bytes"}],"name":"safeBatchTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"}
+]`
+
+// GetMultiTokenBalances returns the balances of owner for each of the given
+// ERC-1155 token ids, in the same order. A single id is queried with
+// balanceOf, several ids with one balanceOfBatch call.
+func GetMultiTokenBalances(contractAddress string, ownerAddress string, ids []*big.Int, network Network) ([]*big.Int, error) {
+	if len(ids) == 0 {
+		return nil, fmt.Errorf("at least one token id is required")
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc1155ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ERC-1155 ABI: %v", err)
+	}
+
+	contract := common.HexToAddress(contractAddress)
+	owner := common.HexToAddress(ownerAddress)
+
+	method := "balanceOf"
+	var callData []byte
+	if len(ids) == 1 {
+		callData, err = parsedABI.Pack(method, owner, ids[0])
+	} else {
+		method = "balanceOfBatch"
+		owners := make([]common.Address, len(ids))
+		for i := range owners {
+			owners[i] = owner
+		}
+		callData, err = parsedABI.Pack(method, owners, ids)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack data for %s call: %v", method, err)
+	}
+
+	var result []byte
+	err = withEndpoints(network, func(client *ethclient.Client) error {
+		var err error
+		result, err = client.CallContract(context.Background(), ethereum.CallMsg{
+			To:   &contract,
+			Data: callData,
+		}, nil)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call contract: %w", err)
+	}
+
+	values, err := parsedABI.Unpack(method, result)
+	if err != nil || len(values) != 1 {
+		return nil, fmt.Errorf("failed to unpack %s result: %v", method, err)
+	}
+
+	if len(ids) == 1 {
+		return []*big.Int{values[0].(*big.Int)}, nil
+	}
+
+	balances := values[0].([]*big.Int)
+	if len(balances) != len(ids) {
+		return nil, fmt.Errorf("balanceOfBatch returned %d balances for %d ids", len(balances), len(ids))
+	}
+	return balances, nil
+}
+
+// SendMultiToken transfers ERC-1155 tokens from the account of the given
+// private key. A single id is sent with safeTransferFrom, several ids with
+// safeBatchTransferFrom. The transfer is simulated first so that a recipient
+// contract which does not accept ERC-1155 tokens is reported before anything
+// is broadcast.
+func SendMultiToken(fromPrivateKey string, contractAddress string, toAddress string, ids []*big.Int, amounts []*big.Int, network Network) (Transaction, error) {
+	if len(ids) == 0 {
+		return Transaction{}, fmt.Errorf("at least one token id is required")
+	}
+	if len(ids) != len(amounts) {
+		return Transaction{}, fmt.Errorf("got %d token ids but %d amounts", len(ids), len(amounts))
+	}
+
+	privateKey, err := parsePrivateKey(fromPrivateKey)
+	if err != nil {
+		return Transaction{}, err
+	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	to := common.HexToAddress(toAddress)
+	contract := common.HexToAddress(contractAddress)
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc1155ABI))
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to parse ERC-1155 ABI: %v", err)
+	}
+
+	method := "safeTransferFrom"
+	var callData []byte
+	if len(ids) == 1 {
+		callData, err = parsedABI.Pack(method, fromAddress, to, ids[0], amounts[0], []byte{})
+	} else {
+		method = "safeBatchTransferFrom"
+		callData, err = parsedABI.Pack(method, fromAddress, to, ids, amounts, []byte{})
+	}
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to pack data for %s call: %v", method, err)
+	}
+
+	// The simulation fails over to other endpoints, the transfer itself is
+	// signed once and only its broadcast is retried
+	var simulateErr error
+	var recipientCode []byte
+	err = withEndpoints(network, func(client *ethclient.Client) error {
+		_, err := client.CallContract(context.Background(), ethereum.CallMsg{
+			From: fromAddress,
+			To:   &contract,
+			Data: callData,
+		}, nil)
+		if err != nil && !isNodeError(err) {
+			return err
+		}
+		simulateErr = err
+		if err != nil {
+			recipientCode, _ = client.CodeAt(context.Background(), to, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to simulate %s: %w", method, err)
+	}
+	if simulateErr != nil {
+		if len(recipientCode) > 0 {
+			return Transaction{}, fmt.Errorf("recipient %s is a contract that does not accept ERC-1155 tokens (onERC1155Received missing or rejected): %v", to.String(), simulateErr)
+		}
+		return Transaction{}, fmt.Errorf("%s would revert: %v", method, simulateErr)
+	}
+
+	client, err := dialNetwork(network)
+	if err != nil {
+		return Transaction{}, err
+	}
+	defer client.Close()
+
+	return sendContractTx(client, privateKey, contract, callData, network)
+}
@@ -0,0 +1,43 @@
+package wallet
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC1155ABI(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc1155ABI))
+	require.NoError(t, err)
+
+	batch := parsedABI.Methods["safeBatchTransferFrom"]
+	require.Equal(t, crypto.Keccak256([]byte("safeBatchTransferFrom(address,address,uint256[],uint256[],bytes)"))[:4], batch.ID)
+
+	balances := parsedABI.Methods["balanceOfBatch"]
+	require.Equal(t, crypto.Keccak256([]byte("balanceOfBatch(address[],uint256[])"))[:4], balances.ID)
+}
+
+func TestSendMultiTokenMismatchedAmounts(t *testing.T) {
+	network := Network{Label: "test", ChainId: 31337, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
+	_, err := SendMultiToken("", "0x0", "0x0", []*big.Int{big.NewInt(1), big.NewInt(2)}, []*big.Int{big.NewInt(1)}, network)
+	require.Error(t, err)
+}
+
+func TestAddMultiTokenToAccount(t *testing.T) {
+	account, err := CreateNewAccount(store, "testaddmultitoken")
+	require.NoError(t, err)
+
+	contract := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	require.NoError(t, AddMultiTokenToAccount(store, account.Label, "sepolia", contract))
+	require.Error(t, AddMultiTokenToAccount(store, account.Label, "sepolia", strings.ToLower(contract)))
+	require.NoError(t, AddMultiTokenToAccount(store, account.Label, "mainnet", contract))
+
+	acc, err := GetAccount(store, account.Label)
+	require.NoError(t, err)
+	require.Equal(t, []string{contract}, acc.MultiTokens["sepolia"])
+	require.Equal(t, []string{contract}, acc.MultiTokens["mainnet"])
+}
@@ -0,0 +1,58 @@
+package wallet
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Errors returned by the wallet package. They are wrapped with the details
+// of the failure, so callers should compare them with errors.Is.
+var (
+	// ErrBucketNotFound is returned when the database has not been
+	// initialized with the bucket an operation needs.
+	ErrBucketNotFound = errors.New("bucket not found")
+
+	ErrAccountNotFound    = errors.New("account not found")
+	ErrAccountExists      = errors.New("account already exists")
+	ErrAccountNotSelected = errors.New("no account selected")
+
+	ErrNetworkNotFound    = errors.New("network not found")
+	ErrNetworkExists      = errors.New("network already exists")
+	ErrNetworkNotSelected = errors.New("network not selected")
+
+	// ErrAccountHasBalance is returned when an account about to be removed
+	// still holds funds on one of the networks.
+	ErrAccountHasBalance = errors.New("account has a balance")
+
+	// ErrInsufficientFunds is returned when the balance of an account does
+	// not cover an amount plus its fees, whether checked locally or reported
+	// by the node.
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrChainIDMismatch is returned when the node behind a network's RPC URL
+	// reports a different chain id than the one stored for the network.
+	ErrChainIDMismatch = errors.New("chain id mismatch")
+
+	// ErrTransactionReverted is returned when a transaction was mined but
+	// its execution failed.
+	ErrTransactionReverted = errors.New("transaction reverted")
+
+	// ErrSecretNotFound is returned when a secret reference cannot be
+	// resolved.
+	ErrSecretNotFound = errors.New("secret not found")
+
+	// ErrNonceUsed is returned when the nonce of a transaction was used by
+	// another transaction, so that it can never be mined.
+	ErrNonceUsed = errors.New("nonce used by another transaction")
+)
+
+// nodeError classifies an error returned by the node. Nodes only report
+// failures as JSON-RPC messages, so known messages are matched to wrap the
+// corresponding error of this package.
+func nodeError(err error) error {
+	if strings.Contains(strings.ToLower(err.Error()), "insufficient funds") {
+		return fmt.Errorf("%w: %v", ErrInsufficientFunds, err)
+	}
+	return err
+}
@@ -0,0 +1,40 @@
+package wallet
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Explorer links follow the paths used by Etherscan and the explorers
+// compatible with it, such as Blockscout.
+
+// TransactionUrl returns the block explorer page of a transaction, or "" if
+// the network has no block explorer.
+func (n Network) TransactionUrl(hash string) string {
+	return n.explorerUrl("tx", hash)
+}
+
+// AddressUrl returns the block explorer page of an address.
+func (n Network) AddressUrl(address string) string {
+	return n.explorerUrl("address", address)
+}
+
+// TokenUrl returns the block explorer page of a token contract.
+func (n Network) TokenUrl(token string) string {
+	return n.explorerUrl("token", token)
+}
+
+func (n Network) explorerUrl(kind, id string) string {
+	if n.BlockExplorerUrl == "" || id == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.TrimRight(n.BlockExplorerUrl, "/"), kind, id)
+}
+
+// validateBlockExplorerUrl accepts an empty value or an http(s) URL.
+func validateBlockExplorerUrl(blockExplorerUrl string) error {
+	if blockExplorerUrl == "" || strings.HasPrefix(blockExplorerUrl, "http://") || strings.HasPrefix(blockExplorerUrl, "https://") {
+		return nil
+	}
+	return fmt.Errorf("blockExplorerUrl must start with 'http://' or 'https://'")
+}
@@ -0,0 +1,479 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// MemoryStore is a Store that keeps everything in memory, for tests and for
+// programs embedding the wallet that manage persistence themselves. Values
+// are stored as JSON like in BoltStore, so both behave the same, including
+// the order of listings.
+type MemoryStore struct {
+	mu              sync.Mutex
+	accounts        map[string][]byte
+	networks        map[string][]byte
+	selectedAccount string
+	selectedNetwork string
+
+	archivedAccounts map[string][]byte
+	archivedNetworks map[string][]byte
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		accounts: make(map[string][]byte),
+		networks: make(map[string][]byte),
+
+		archivedAccounts: make(map[string][]byte),
+		archivedNetworks: make(map[string][]byte),
+	}
+}
+
+func (s *MemoryStore) Close() error {
+	return nil
+}
+
+func copyValues(values map[string][]byte) map[string][]byte {
+	copied := make(map[string][]byte, len(values))
+	for key, value := range values {
+		copied[key] = value
+	}
+	return copied
+}
+
+// Transaction runs update on a copy of the store, which replaces the store
+// if update succeeds. Values are never changed in place, so the maps are
+// copied shallowly.
+func (s *MemoryStore) Transaction(update func(store Store) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	staged := &MemoryStore{
+		accounts:        copyValues(s.accounts),
+		networks:        copyValues(s.networks),
+		selectedAccount: s.selectedAccount,
+		selectedNetwork: s.selectedNetwork,
+
+		archivedAccounts: copyValues(s.archivedAccounts),
+		archivedNetworks: copyValues(s.archivedNetworks),
+	}
+	err := update(staged)
+	if err != nil {
+		return err
+	}
+
+	s.accounts, s.networks = staged.accounts, staged.networks
+	s.selectedAccount, s.selectedNetwork = staged.selectedAccount, staged.selectedNetwork
+	s.archivedAccounts, s.archivedNetworks = staged.archivedAccounts, staged.archivedNetworks
+	return nil
+}
+
+func sortedKeys(values map[string][]byte) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// removeKey deletes label from values and returns the new selection, the
+// first remaining key if label was selected.
+func removeKey(values map[string][]byte, selected string, label string) string {
+	delete(values, label)
+	if selected != label {
+		return selected
+	}
+	keys := sortedKeys(values)
+	if len(keys) == 0 {
+		return ""
+	}
+	return keys[0]
+}
+
+// moveKey moves label from one map to another, failing with exists if the
+// destination has the label already.
+func moveKey(from, to map[string][]byte, label string, notFound, exists error) error {
+	value, ok := from[label]
+	if !ok {
+		return fmt.Errorf("%w: %s", notFound, label)
+	}
+	if _, ok := to[label]; ok {
+		return fmt.Errorf("%w: %s", exists, label)
+	}
+	to[label] = value
+	delete(from, label)
+	return nil
+}
+
+// listValues decodes every value of a map, sorted by key.
+func listValues(values map[string][]byte, decode func(v []byte) error) error {
+	for _, key := range sortedKeys(values) {
+		err := decode(values[key])
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) getAccount(label string) (Account, error) {
+	var account Account
+
+	accountJSON, ok := s.accounts[label]
+	if !ok {
+		return account, fmt.Errorf("%w: %s", ErrAccountNotFound, label)
+	}
+
+	err := json.Unmarshal(accountJSON, &account)
+	if err != nil {
+		return account, fmt.Errorf("json unmarshal: %s", err)
+	}
+
+	account.Selected = s.selectedAccount == label
+	return account, nil
+}
+
+func (s *MemoryStore) putAccount(account Account) error {
+	accountJSON, err := json.Marshal(account)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+	s.accounts[account.Label] = accountJSON
+	return nil
+}
+
+func (s *MemoryStore) GetAccount(label string) (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getAccount(label)
+}
+
+func (s *MemoryStore) ListAccounts() ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var accounts []Account
+	for _, label := range sortedKeys(s.accounts) {
+		account, err := s.getAccount(label)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, account)
+	}
+	return accounts, nil
+}
+
+func (s *MemoryStore) ImportAccount(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[account.Label]; ok {
+		return fmt.Errorf("%w: %s", ErrAccountExists, account.Label)
+	}
+
+	err := s.putAccount(account)
+	if err != nil {
+		return err
+	}
+	s.selectedAccount = account.Label
+	return nil
+}
+
+func (s *MemoryStore) UpdateAccount(label string, update func(*Account) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	account, err := s.getAccount(label)
+	if err != nil {
+		return err
+	}
+
+	err = update(&account)
+	if err != nil {
+		return err
+	}
+	if account.Label != label {
+		return fmt.Errorf("account %s cannot be renamed to %s", label, account.Label)
+	}
+
+	return s.putAccount(account)
+}
+
+func (s *MemoryStore) RemoveAccount(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[label]; !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, label)
+	}
+	s.selectedAccount = removeKey(s.accounts, s.selectedAccount, label)
+	return nil
+}
+
+func (s *MemoryStore) ArchiveAccount(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.accounts[label]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, label)
+	}
+	if _, ok := s.archivedAccounts[label]; ok {
+		return fmt.Errorf("%w: %s", ErrAccountExists, label)
+	}
+	s.archivedAccounts[label] = value
+	s.selectedAccount = removeKey(s.accounts, s.selectedAccount, label)
+	return nil
+}
+
+func (s *MemoryStore) UnarchiveAccount(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return moveKey(s.archivedAccounts, s.accounts, label, ErrAccountNotFound, ErrAccountExists)
+}
+
+func (s *MemoryStore) PutArchivedAccount(account Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	accountJSON, err := json.Marshal(account)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+	s.archivedAccounts[account.Label] = accountJSON
+	return nil
+}
+
+func (s *MemoryStore) ListArchivedAccounts() ([]Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var accounts []Account
+	err := listValues(s.archivedAccounts, func(v []byte) error {
+		var account Account
+		err := json.Unmarshal(v, &account)
+		if err != nil {
+			return fmt.Errorf("json unmarshal: %s", err)
+		}
+		accounts = append(accounts, account)
+		return nil
+	})
+	return accounts, err
+}
+
+func (s *MemoryStore) SelectAccount(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[label]; !ok {
+		return fmt.Errorf("%w: %s", ErrAccountNotFound, label)
+	}
+	s.selectedAccount = label
+	return nil
+}
+
+func (s *MemoryStore) GetSelectedAccount() (Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[s.selectedAccount]; !ok {
+		return Account{}, ErrAccountNotSelected
+	}
+	return s.getAccount(s.selectedAccount)
+}
+
+func (s *MemoryStore) getNetwork(label string) (Network, error) {
+	var network Network
+
+	networkJSON, ok := s.networks[label]
+	if !ok {
+		return network, fmt.Errorf("%w: %s", ErrNetworkNotFound, label)
+	}
+
+	err := json.Unmarshal(networkJSON, &network)
+	if err != nil {
+		return network, fmt.Errorf("json unmarshal: %s", err)
+	}
+
+	network.Selected = s.selectedNetwork == label
+	return network, nil
+}
+
+func (s *MemoryStore) putNetwork(network Network) error {
+	networkJSON, err := json.Marshal(network)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+	s.networks[network.Label] = networkJSON
+	return nil
+}
+
+func (s *MemoryStore) GetNetwork(label string) (Network, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.getNetwork(label)
+}
+
+func (s *MemoryStore) ListNetworks() ([]Network, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var networks []Network
+	for _, label := range sortedKeys(s.networks) {
+		network, err := s.getNetwork(label)
+		if err != nil {
+			return nil, err
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+func (s *MemoryStore) AddNetwork(network Network) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.networks[network.Label]; ok {
+		return fmt.Errorf("%w: %s", ErrNetworkExists, network.Label)
+	}
+
+	err := s.putNetwork(network)
+	if err != nil {
+		return err
+	}
+	s.selectedNetwork = network.Label
+	return nil
+}
+
+func (s *MemoryStore) UpdateNetwork(label string, update func(*Network) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	network, err := s.getNetwork(label)
+	if err != nil {
+		return err
+	}
+
+	err = update(&network)
+	if err != nil {
+		return err
+	}
+	if network.Label == label {
+		return s.putNetwork(network)
+	}
+
+	// Rename, moving the selection and the tracked contracts of every account
+	if _, ok := s.networks[network.Label]; ok {
+		return fmt.Errorf("%w: %s", ErrNetworkExists, network.Label)
+	}
+	err = s.putNetwork(network)
+	if err != nil {
+		return err
+	}
+	delete(s.networks, label)
+	if s.selectedNetwork == label {
+		s.selectedNetwork = network.Label
+	}
+	for _, key := range sortedKeys(s.accounts) {
+		account, err := s.getAccount(key)
+		if err != nil {
+			return err
+		}
+		if renameMultiTokens(&account, label, network.Label) {
+			err = s.putAccount(account)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) DeleteNetwork(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.networks[label]; !ok {
+		return fmt.Errorf("%w: %s", ErrNetworkNotFound, label)
+	}
+	s.selectedNetwork = removeKey(s.networks, s.selectedNetwork, label)
+	return nil
+}
+
+func (s *MemoryStore) ArchiveNetwork(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.networks[label]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrNetworkNotFound, label)
+	}
+	if _, ok := s.archivedNetworks[label]; ok {
+		return fmt.Errorf("%w: %s", ErrNetworkExists, label)
+	}
+	s.archivedNetworks[label] = value
+	s.selectedNetwork = removeKey(s.networks, s.selectedNetwork, label)
+	return nil
+}
+
+func (s *MemoryStore) UnarchiveNetwork(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return moveKey(s.archivedNetworks, s.networks, label, ErrNetworkNotFound, ErrNetworkExists)
+}
+
+func (s *MemoryStore) PutArchivedNetwork(network Network) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	networkJSON, err := json.Marshal(network)
+	if err != nil {
+		return fmt.Errorf("json marshal: %s", err)
+	}
+	s.archivedNetworks[network.Label] = networkJSON
+	return nil
+}
+
+func (s *MemoryStore) ListArchivedNetworks() ([]Network, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var networks []Network
+	err := listValues(s.archivedNetworks, func(v []byte) error {
+		var network Network
+		err := json.Unmarshal(v, &network)
+		if err != nil {
+			return fmt.Errorf("json unmarshal: %s", err)
+		}
+		networks = append(networks, network)
+		return nil
+	})
+	return networks, err
+}
+
+func (s *MemoryStore) SelectNetwork(label string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.networks[label]; !ok {
+		return fmt.Errorf("%w: %s", ErrNetworkNotFound, label)
+	}
+	s.selectedNetwork = label
+	return nil
+}
+
+func (s *MemoryStore) GetSelectedNetwork() (Network, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.networks[s.selectedNetwork]; !ok {
+		return Network{}, ErrNetworkNotSelected
+	}
+	return s.getNetwork(s.selectedNetwork)
+}
@@ -0,0 +1,229 @@
+package wallet
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/boltdb/bolt"
+)
+
+// SchemaVersion is the version of the database layout written by this
+// version of the wallet.
+const SchemaVersion = 1
+
+// ErrSchemaTooNew is returned when a database was written by a newer version
+// of the wallet, which this version could corrupt.
+var ErrSchemaTooNew = errors.New("database schema is newer than supported")
+
+var (
+	metaBucket       = []byte("meta")
+	schemaVersionKey = []byte("schema_version")
+)
+
+// migration upgrades the database from version-1 to version and returns the
+// number of records it changed.
+type migration struct {
+	version     int
+	description string
+	migrate     func(tx *bolt.Tx) (int, error)
+}
+
+// migrations are applied in order. A released migration must never change,
+// new ones are appended with the next version.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "rename account fields pubic, private and Tokens, stop storing selection flags",
+		migrate:     migrateFieldNames,
+	},
+}
+
+// MigrationResult describes a migration that was, or would be, applied.
+type MigrationResult struct {
+	Version     int
+	Description string
+	Records     int
+}
+
+// errDryRun rolls back the transaction of a dry run.
+var errDryRun = errors.New("dry run")
+
+// GetSchemaVersion returns the schema version of the database. Databases
+// written before versioning are version 0.
+func GetSchemaVersion(db *bolt.DB) (int, error) {
+	var version int
+	err := db.View(func(tx *bolt.Tx) error {
+		var err error
+		version, err = schemaVersion(tx)
+		return err
+	})
+	return version, err
+}
+
+func schemaVersion(tx *bolt.Tx) (int, error) {
+	bucket := tx.Bucket(metaBucket)
+	if bucket == nil {
+		return 0, nil
+	}
+	value := bucket.Get(schemaVersionKey)
+	if value == nil {
+		return 0, nil
+	}
+	version, err := strconv.Atoi(string(value))
+	if err != nil {
+		return 0, fmt.Errorf("invalid schema version %q", value)
+	}
+	return version, nil
+}
+
+func setSchemaVersion(tx *bolt.Tx, version int) error {
+	bucket, err := tx.CreateBucketIfNotExists(metaBucket)
+	if err != nil {
+		return fmt.Errorf("create bucket: %s", err)
+	}
+	return bucket.Put(schemaVersionKey, []byte(strconv.Itoa(version)))
+}
+
+// isEmpty reports whether the database holds nothing yet, in which case it
+// is created at the current version instead of being migrated.
+func isEmpty(tx *bolt.Tx) bool {
+	empty := true
+	tx.ForEach(func(name []byte, _ *bolt.Bucket) error {
+		empty = false
+		return nil
+	})
+	return empty
+}
+
+// Migrate upgrades the database to SchemaVersion. All pending migrations run
+// in a single transaction, so the database is either fully migrated or left
+// untouched. With dryRun nothing is written and the migrations that would be
+// applied are returned. Databases with a newer schema are refused.
+func Migrate(db *bolt.DB, dryRun bool) ([]MigrationResult, error) {
+	var results []MigrationResult
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		if isEmpty(tx) {
+			if dryRun {
+				return errDryRun
+			}
+			return setSchemaVersion(tx, SchemaVersion)
+		}
+
+		version, err := schemaVersion(tx)
+		if err != nil {
+			return err
+		}
+		if version > SchemaVersion {
+			return fmt.Errorf("%w: database is at version %d, this wallet supports up to %d", ErrSchemaTooNew, version, SchemaVersion)
+		}
+
+		for _, m := range migrations {
+			if m.version <= version {
+				continue
+			}
+			records, err := m.migrate(tx)
+			if err != nil {
+				return fmt.Errorf("migration %d: %w", m.version, err)
+			}
+			results = append(results, MigrationResult{Version: m.version, Description: m.description, Records: records})
+		}
+
+		if dryRun {
+			return errDryRun
+		}
+		if version == SchemaVersion {
+			return nil
+		}
+		return setSchemaVersion(tx, SchemaVersion)
+	})
+	if errors.Is(err, errDryRun) {
+		err = nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// rewriteRecords applies rewrite to the JSON object of every record in a
+// bucket and stores the records it changed.
+func rewriteRecords(tx *bolt.Tx, name []byte, rewrite func(record map[string]json.RawMessage) bool) (int, error) {
+	bucket := tx.Bucket(name)
+	if bucket == nil {
+		return 0, nil
+	}
+
+	updated := make(map[string][]byte)
+	err := bucket.ForEach(func(k, v []byte) error {
+		var record map[string]json.RawMessage
+		err := json.Unmarshal(v, &record)
+		if err != nil {
+			return fmt.Errorf("json unmarshal %s: %s", k, err)
+		}
+		if !rewrite(record) {
+			return nil
+		}
+		value, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("json marshal %s: %s", k, err)
+		}
+		updated[string(k)] = value
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// Keys are written after iterating, bolt does not allow changing a bucket
+	// while walking it
+	for k, v := range updated {
+		err := bucket.Put([]byte(k), v)
+		if err != nil {
+			return 0, err
+		}
+	}
+	return len(updated), nil
+}
+
+func renameField(record map[string]json.RawMessage, from, to string) bool {
+	value, ok := record[from]
+	if !ok {
+		return false
+	}
+	delete(record, from)
+	if _, exists := record[to]; !exists {
+		record[to] = value
+	}
+	return true
+}
+
+func deleteField(record map[string]json.RawMessage, name string) bool {
+	_, ok := record[name]
+	delete(record, name)
+	return ok
+}
+
+func migrateFieldNames(tx *bolt.Tx) (int, error) {
+	accounts, err := rewriteRecords(tx, accountsBucket, func(record map[string]json.RawMessage) bool {
+		changed := renameField(record, "pubic", "address")
+		changed = renameField(record, "private", "privateKey") || changed
+		changed = renameField(record, "Tokens", "tokens") || changed
+		changed = deleteField(record, "Selected") || changed
+		return changed
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	networks, err := rewriteRecords(tx, networksBucket, func(record map[string]json.RawMessage) bool {
+		return deleteField(record, "selected")
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return accounts + networks, nil
+}
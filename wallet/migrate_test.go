@@ -0,0 +1,95 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+// newLegacyDB writes a database the way wallets before schema versioning did.
+func newLegacyDB(t *testing.T) (*bolt.DB, string) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := bolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		accounts, err := tx.CreateBucketIfNotExists(accountsBucket)
+		require.NoError(t, err)
+		require.NoError(t, accounts.Put([]byte("main"), []byte(`{"label":"main","pubic":"0xabc","private":"key","Tokens":["0xdef"],"Selected":true}`)))
+
+		networks, err := tx.CreateBucketIfNotExists(networksBucket)
+		require.NoError(t, err)
+		require.NoError(t, networks.Put([]byte("local"), []byte(`{"label":"local","chainId":1337,"symbol":"ETH","rpcUrl":"http://localhost:8545","selected":true}`)))
+
+		selected, err := tx.CreateBucketIfNotExists(selectedBucket)
+		require.NoError(t, err)
+		require.NoError(t, selected.Put(selectedAccountKey, []byte("main")))
+		return selected.Put(selectedNetworkKey, []byte("local"))
+	})
+	require.NoError(t, err)
+	return db, path
+}
+
+func TestMigrateDryRun(t *testing.T) {
+	db, _ := newLegacyDB(t)
+	defer db.Close()
+
+	results, err := Migrate(db, true)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	require.Equal(t, 1, results[0].Version)
+	require.Equal(t, 2, results[0].Records)
+
+	version, err := GetSchemaVersion(db)
+	require.NoError(t, err)
+	require.Equal(t, 0, version)
+}
+
+func TestMigrateLegacyDatabase(t *testing.T) {
+	db, path := newLegacyDB(t)
+	db.Close()
+
+	store, err := OpenBoltStore(path)
+	require.NoError(t, err)
+	defer store.Close()
+
+	version, err := GetSchemaVersion(store.db)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, version)
+
+	account, err := store.GetSelectedAccount()
+	require.NoError(t, err)
+	require.Equal(t, "0xabc", account.Publicy)
+	require.Equal(t, "key", account.Privatey)
+	require.Equal(t, []string{"0xdef"}, account.Tokens)
+
+	network, err := store.GetSelectedNetwork()
+	require.NoError(t, err)
+	require.Equal(t, 1337, network.ChainId)
+
+	// Running the migrations again changes nothing
+	results, err := Migrate(store.db, false)
+	require.NoError(t, err)
+	require.Empty(t, results)
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	store := newTestBoltStore(t)
+	err := store.db.Update(func(tx *bolt.Tx) error {
+		return setSchemaVersion(tx, SchemaVersion+1)
+	})
+	require.NoError(t, err)
+
+	_, err = Migrate(store.db, false)
+	require.ErrorIs(t, err, ErrSchemaTooNew)
+}
+
+func TestNewDatabaseStartsAtCurrentVersion(t *testing.T) {
+	store := newTestBoltStore(t)
+
+	version, err := GetSchemaVersion(store.db)
+	require.NoError(t, err)
+	require.Equal(t, SchemaVersion, version)
+}
@@ -1,205 +1,154 @@
 package wallet
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
-
-	"github.com/boltdb/bolt"
 )
 
 type Network struct {
-	Label    string `json:"label"`
-	ChainId  int    `json:"chainId"`
-	Symbol   string `json:"symbol"`
-	RpcUrl   string `json:"rpcUrl"`
-	Selected bool   `json:"selected"`
+	Label   string `json:"label"`
+	ChainId int    `json:"chainId"`
+	Symbol  string `json:"symbol"`
+	RpcUrl  string `json:"rpcUrl"`
+	// RpcUrls are fallback endpoints, tried in order when RpcUrl fails
+	RpcUrls []string `json:"rpcUrls,omitempty"`
+	// Headers are sent to every endpoint, with values given as secret
+	// references resolved when connecting
+	Headers   map[string]string `json:"headers,omitempty"`
+	BasicAuth *BasicAuth        `json:"basicAuth,omitempty"`
+	// BlockExplorerUrl is the base URL of an Etherscan compatible explorer
+	BlockExplorerUrl string `json:"blockExplorerUrl,omitempty"`
+	// Selected is derived from the store's selection and never persisted
+	Selected bool `json:"-"`
 }
 
-// addNetwork adds a new network to the Bolt database
-func AddNetwork(db *bolt.DB, network Network) error {
-	// Validate the RpcUrl
-	if !strings.HasPrefix(network.RpcUrl, "http://") && !strings.HasPrefix(network.RpcUrl, "https://") {
-		return fmt.Errorf("rpcUrl must start with 'http://' or 'https://'")
+// AddNetwork adds a new network to the database and selects it
+func AddNetwork(store Store, network Network) error {
+	err := validateNetwork(network)
+	if err != nil {
+		return err
 	}
-	network.Selected = true
-	// Open a writable transaction
-	err := db.Update(func(tx *bolt.Tx) error {
-		// Create or get the bucket "networks"
-		bucket, err := tx.CreateBucketIfNotExists([]byte("networks"))
-		if err != nil {
-			return fmt.Errorf("create bucket: %s", err)
-		}
-
-		// Check if the network with the same label already exists
-		existing := bucket.Get([]byte(network.Label))
-		if existing != nil {
-			return fmt.Errorf("network with label %s already exists", network.Label)
-		}
+	return store.AddNetwork(network)
+}
 
-		// Marshal the network object to JSON
-		networkJSON, err := json.Marshal(network)
+// validateNetwork checks the label, the endpoints, the credentials and the
+// block explorer of a network.
+func validateNetwork(network Network) error {
+	if network.Label == "" {
+		return fmt.Errorf("network label must not be empty")
+	}
+	for _, endpoint := range network.Endpoints() {
+		err := ValidateRpcUrl(endpoint)
 		if err != nil {
-			return fmt.Errorf("json marshal: %s", err)
+			return err
 		}
-
-		// Use the label as the key and store the JSON as the value
-		return bucket.Put([]byte(network.Label), networkJSON)
-	})
-
+	}
+	err := network.validateCredentials()
 	if err != nil {
 		return err
 	}
-
-	return SelectNetwork(db, network.Label)
+	return validateBlockExplorerUrl(network.BlockExplorerUrl)
 }
 
-// listNetworks retrieves all networks from the Bolt database
-func ListNetworks(db *bolt.DB) ([]Network, error) {
-	var networks []Network
-
-	// Open a read-only transaction
-	err := db.View(func(tx *bolt.Tx) error {
-		// Get the bucket "networks"
-		bucket := tx.Bucket([]byte("networks"))
-		if bucket == nil {
-			return nil
+// ValidateRpcUrl accepts the transports supported by ethclient.Dial: http(s)
+// and ws(s) URLs, and absolute paths to an IPC socket. URLs must not hold a
+// password or an API key, which would be stored as is.
+func ValidateRpcUrl(rpcUrl string) error {
+	for _, scheme := range []string{"http://", "https://", "ws://", "wss://"} {
+		if strings.HasPrefix(rpcUrl, scheme) {
+			return validateUrlCredentials(rpcUrl)
 		}
+	}
+	if filepath.IsAbs(rpcUrl) {
+		return nil
+	}
+	return fmt.Errorf("rpcUrl must be an http(s) or ws(s) URL or an absolute IPC socket path")
+}
 
-		// Iterate through all key-value pairs in the bucket
-		return bucket.ForEach(func(k, v []byte) error {
-			var network Network
-			// Unmarshal the JSON value to a Network object
-			err := json.Unmarshal(v, &network)
-			if err != nil {
-				return fmt.Errorf("json unmarshal: %s", err)
-			}
-			// Append to the list of networks
-			networks = append(networks, network)
-			return nil
-		})
-	})
-
-	return networks, err
+// ListNetworks retrieves all networks from the database
+func ListNetworks(store Store) ([]Network, error) {
+	return store.ListNetworks()
 }
 
-// deleteNetwork removes a network from the Bolt database
-func DeleteNetwork(db *bolt.DB, label string) error {
-	// Open a writable transaction
-	err := db.Update(func(tx *bolt.Tx) error {
-		// Get the bucket "networks"
-		bucket := tx.Bucket([]byte("networks"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
+// DeleteNetwork removes a network from the database, selecting another one
+// if it was selected
+func DeleteNetwork(store Store, label string) error {
+	return store.DeleteNetwork(label)
+}
 
-		// Delete the key-value pair with the given label
-		return bucket.Delete([]byte(label))
-	})
+// ArchiveNetwork moves a network to the archive, selecting another one if it
+// was selected. Accounts keep the contracts they track on it.
+func ArchiveNetwork(store Store, label string) error {
+	return store.ArchiveNetwork(label)
+}
 
-	return err
+// UnarchiveNetwork restores an archived network.
+func UnarchiveNetwork(store Store, label string) error {
+	return store.UnarchiveNetwork(label)
 }
 
-func UpdateNetwork(db *bolt.DB, network Network) error {
-	err := db.Update(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("networks"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
+func ListArchivedNetworks(store Store) ([]Network, error) {
+	return store.ListArchivedNetworks()
+}
 
-		networkJSON, err := json.Marshal(network)
+// UpdateNetwork applies update to a network and validates the result like
+// AddNetwork. Changing the label renames the network, keeping its selection
+// and the contracts accounts track on it.
+func UpdateNetwork(store Store, label string, update func(*Network) error) error {
+	return store.UpdateNetwork(label, func(network *Network) error {
+		err := update(network)
 		if err != nil {
-			return fmt.Errorf("json marshal: %s", err)
+			return err
 		}
-
-		return bucket.Put([]byte(network.Label), networkJSON)
+		return validateNetwork(*network)
 	})
-
-	return err
 }
 
-func GetNetwork(db *bolt.DB, label string) (Network, error) {
-	var network Network
-
-	err := db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("networks"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
-		}
-
-		networkJSON := bucket.Get([]byte(label))
-		if networkJSON == nil {
-			return fmt.Errorf("network not found")
-		}
-
-		err := json.Unmarshal(networkJSON, &network)
-		if err != nil {
-			return fmt.Errorf("json unmarshal: %s", err)
-		}
+func GetNetwork(store Store, label string) (Network, error) {
+	return store.GetNetwork(label)
+}
 
-		return nil
-	})
+func SelectNetwork(store Store, label string) error {
+	return store.SelectNetwork(label)
+}
 
-	return network, err
+func GetSelectedNetwork(store Store) (Network, error) {
+	return store.GetSelectedNetwork()
 }
 
-func SelectNetwork(db *bolt.DB, label string) error {
-	// deselect currente selected network
-	selectedNetwork, err := GetSelectedNetwork(db)
-	if err == nil {
-		selectedNetwork.Selected = false
-		err = UpdateNetwork(db, selectedNetwork)
-		if err != nil {
-			return err
-		}
+// FindNetwork returns the network with the label or chain id given, without
+// changing the selection. A chain id shared by several networks is
+// ambiguous and has to be given as a label.
+func FindNetwork(store Store, labelOrChainID string) (Network, error) {
+	network, err := store.GetNetwork(labelOrChainID)
+	if !errors.Is(err, ErrNetworkNotFound) {
+		return network, err
 	}
-
-	network, err := GetNetwork(db, label)
-	if err != nil {
-		return err
+	chainID, convErr := strconv.Atoi(labelOrChainID)
+	if convErr != nil {
+		return network, err
 	}
-	err = db.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte("selected"))
-		if err != nil {
-			return fmt.Errorf("create bucket: %s", err)
-		}
 
-		return bucket.Put([]byte("network"), []byte(label))
-	})
+	networks, err := store.ListNetworks()
 	if err != nil {
-		return err
+		return Network{}, err
 	}
-
-	network.Selected = true
-	return UpdateNetwork(db, network)
-}
-
-func GetSelectedNetwork(db *bolt.DB) (Network, error) {
-	var network Network
-
-	err := db.View(func(tx *bolt.Tx) error {
-		bucket := tx.Bucket([]byte("selected"))
-		if bucket == nil {
-			return fmt.Errorf("bucket not found")
+	var found []Network
+	var labels []string
+	for _, network := range networks {
+		if network.ChainId == chainID {
+			found = append(found, network)
+			labels = append(labels, network.Label)
 		}
-
-		label := bucket.Get([]byte("network"))
-		if label == nil {
-			return fmt.Errorf("network not selected")
-		}
-
-		networkJSON := tx.Bucket([]byte("networks")).Get(label)
-		if networkJSON == nil {
-			return fmt.Errorf("network not found")
-		}
-
-		err := json.Unmarshal(networkJSON, &network)
-		if err != nil {
-			return fmt.Errorf("json unmarshal: %s", err)
-		}
-
-		return nil
-	})
-
-	return network, err
+	}
+	switch len(found) {
+	case 0:
+		return Network{}, fmt.Errorf("%w: %s", ErrNetworkNotFound, labelOrChainID)
+	case 1:
+		return found[0], nil
+	}
+	return Network{}, fmt.Errorf("chain id %d is used by networks %s, give a label instead", chainID, strings.Join(labels, ", "))
 }
@@ -2,41 +2,36 @@
 package wallet
 
 import (
-	"log"
+	"errors"
 	"os"
 	"testing"
 
 	"github.com/EliasManj/go-wallet/utils"
-	"github.com/boltdb/bolt"
 	"github.com/stretchr/testify/require"
 )
 
 var (
-	db *bolt.DB
+	store Store
 )
 
 func TestMain(m *testing.M) {
-	// Setup: Open a temporary Bolt database
-	var err error
-	db, err = bolt.Open("test.db", 0600, nil)
-	if err != nil {
-		log.Fatalf("Failed to open test database: %s", err)
-	}
+	// Setup: the tests share an in-memory store, the Bolt implementation is
+	// covered by store_test.go
+	store = NewMemoryStore()
 
 	code := m.Run()
 
-	db.Close()
-	os.Remove("test.db")
+	store.Close()
 
 	os.Exit(code)
 }
 
 func TestAddNetwork(t *testing.T) {
 	network := Network{Label: "test", ChainId: 123, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
-	if err := AddNetwork(db, network); err != nil {
+	if err := AddNetwork(store, network); err != nil {
 		t.Fatalf("Failed to add network: %s", err)
 	}
-	net, err := GetNetwork(db, network.Label)
+	net, err := GetNetwork(store, network.Label)
 	require.NoError(t, err)
 	require.Equal(t, network.Label, net.Label)
 	require.Equal(t, network.ChainId, net.ChainId)
@@ -48,34 +43,41 @@ func TestAddNetwork(t *testing.T) {
 func TestAddNetworkWithSameLabel(t *testing.T) {
 	// add network
 	network := Network{Label: "same", ChainId: 123, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
-	if err := AddNetwork(db, network); err != nil {
+	if err := AddNetwork(store, network); err != nil {
 		t.Fatalf("Failed to add network: %s", err)
 	}
 	// add network with the same label
-	if err := AddNetwork(db, network); err == nil {
-		t.Fatalf("Expected error when adding network with the same label")
+	if err := AddNetwork(store, network); !errors.Is(err, ErrNetworkExists) {
+		t.Fatalf("Expected ErrNetworkExists when adding network with the same label, got %v", err)
+	}
+}
+
+func TestGetMissingNetwork(t *testing.T) {
+	_, err := GetNetwork(store, "missing")
+	if !errors.Is(err, ErrNetworkNotFound) {
+		t.Fatalf("Expected ErrNetworkNotFound, got %v", err)
 	}
 }
 
 func TestAddAndDeleteNetwork(t *testing.T) {
 	// add network
 	network := Network{Label: "delete", ChainId: 123, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
-	if err := AddNetwork(db, network); err != nil {
+	if err := AddNetwork(store, network); err != nil {
 		t.Fatalf("Failed to add network: %s", err)
 	}
 	// delete the network
-	if err := DeleteNetwork(db, network.Label); err != nil {
+	if err := DeleteNetwork(store, network.Label); err != nil {
 		t.Fatalf("Failed to delete network: %s", err)
 	}
 }
 
 func TestAddAndListNetworks(t *testing.T) {
 
-	todel, err := ListNetworks(db)
+	todel, err := ListNetworks(store)
 	require.NoError(t, err)
 
 	for _, network := range todel {
-		err := DeleteNetwork(db, network.Label)
+		err := DeleteNetwork(store, network.Label)
 		require.NoError(t, err)
 	}
 
@@ -87,13 +89,13 @@ func TestAddAndListNetworks(t *testing.T) {
 
 	// Test AddNetwork function
 	for _, network := range testNetworks {
-		if err := AddNetwork(db, network); err != nil {
+		if err := AddNetwork(store, network); err != nil {
 			t.Fatalf("Failed to add network: %s", err)
 		}
 	}
 
 	// Test listNetworks function
-	networks, err := ListNetworks(db)
+	networks, err := ListNetworks(store)
 	if err != nil {
 		t.Fatalf("Failed to list networks: %s", err)
 	}
@@ -120,7 +122,7 @@ func TestAddAndListNetworks(t *testing.T) {
 func TestAddNetworkWithInvalidRpcUrl(t *testing.T) {
 	// add network with invalid rpcUrl
 	network := Network{Label: "invalid", ChainId: 123, Symbol: "ETH", RpcUrl: "localhost:8545"}
-	if err := AddNetwork(db, network); err == nil {
+	if err := AddNetwork(store, network); err == nil {
 		t.Fatalf("Expected error when adding network with invalid rpcUrl")
 	}
 }
@@ -130,21 +132,21 @@ func TestOnlyOneSelectedNetwork(t *testing.T) {
 	net2 := Network{Label: utils.CreateAccountLabel("testonly1sel2"), ChainId: 456, Symbol: "BTC", RpcUrl: "http://localhost:8545", Selected: true}
 	net3 := Network{Label: utils.CreateAccountLabel("testonly1sel3"), ChainId: 456, Symbol: "BTC", RpcUrl: "http://localhost:8545", Selected: true}
 
-	require.NoError(t, AddNetwork(db, net1))
-	require.NoError(t, AddNetwork(db, net2))
-	require.NoError(t, AddNetwork(db, net3))
+	require.NoError(t, AddNetwork(store, net1))
+	require.NoError(t, AddNetwork(store, net2))
+	require.NoError(t, AddNetwork(store, net3))
 
-	SelectNetwork(db, net1.Label)
-	SelectNetwork(db, net2.Label)
-	SelectNetwork(db, net3.Label)
+	SelectNetwork(store, net1.Label)
+	SelectNetwork(store, net2.Label)
+	SelectNetwork(store, net3.Label)
 
-	n1, err := GetNetwork(db, net1.Label)
+	n1, err := GetNetwork(store, net1.Label)
 	require.NoError(t, err)
 	require.Equal(t, net1.Label, n1.Label)
-	n2, err := GetNetwork(db, net2.Label)
+	n2, err := GetNetwork(store, net2.Label)
 	require.NoError(t, err)
 	require.Equal(t, net2.Label, n2.Label)
-	n3, err := GetNetwork(db, net3.Label)
+	n3, err := GetNetwork(store, net3.Label)
 	require.NoError(t, err)
 	require.Equal(t, net3.Label, n3.Label)
 
@@ -152,16 +154,16 @@ func TestOnlyOneSelectedNetwork(t *testing.T) {
 	require.Equal(t, false, n2.Selected)
 	require.Equal(t, true, n3.Selected)
 
-	selected, err := GetSelectedNetwork(db)
+	selected, err := GetSelectedNetwork(store)
 	require.NoError(t, err)
 	require.Equal(t, net3.Label, selected.Label)
 }
 
 func TestAddNetworkAndGetSelected(t *testing.T) {
 	network := Network{Label: "addandtestselected", ChainId: 123, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
-	require.NoError(t, AddNetwork(db, network))
+	require.NoError(t, AddNetwork(store, network))
 
-	net, err := GetSelectedNetwork(db)
+	net, err := GetSelectedNetwork(store)
 	require.NoError(t, err)
 	require.Equal(t, network.Label, net.Label)
 	require.Equal(t, network.ChainId, net.ChainId)
@@ -171,15 +173,57 @@ func TestAddNetworkAndGetSelected(t *testing.T) {
 }
 
 func TestNoNetworksToList(t *testing.T) {
-	networks, err := ListNetworks(db)
+	networks, err := ListNetworks(store)
 	require.NoError(t, err)
 
 	for _, network := range networks {
-		err := DeleteNetwork(db, network.Label)
+		err := DeleteNetwork(store, network.Label)
 		require.NoError(t, err)
 	}
 
-	_, err = ListNetworks(db)
+	_, err = ListNetworks(store)
+	require.NoError(t, err)
+
+}
+
+func TestBlockExplorerUrls(t *testing.T) {
+	store := NewMemoryStore()
+	network := Network{Label: "mainnet", ChainId: 1, Symbol: "ETH", RpcUrl: "https://rpc.example.org", BlockExplorerUrl: "https://etherscan.io/"}
+	require.NoError(t, AddNetwork(store, network))
+
+	stored, err := GetNetwork(store, "mainnet")
 	require.NoError(t, err)
+	require.Equal(t, "https://etherscan.io/tx/0xabc", stored.TransactionUrl("0xabc"))
+	require.Equal(t, "https://etherscan.io/address/0x01", stored.AddressUrl("0x01"))
+	require.Equal(t, "https://etherscan.io/token/0x02", stored.TokenUrl("0x02"))
+
+	// Networks without an explorer have no links
+	require.Empty(t, Network{}.TransactionUrl("0xabc"))
+
+	network.Label = "invalid"
+	network.BlockExplorerUrl = "etherscan.io"
+	require.Error(t, AddNetwork(store, network))
+}
+
+func TestFindNetwork(t *testing.T) {
+	store := NewMemoryStore()
+	require.NoError(t, store.AddNetwork(Network{Label: "mainnet", ChainId: 1}))
+	require.NoError(t, store.AddNetwork(Network{Label: "sepolia", ChainId: 11155111}))
+	require.NoError(t, store.AddNetwork(Network{Label: "sepolia-archive", ChainId: 11155111}))
+
+	network, err := FindNetwork(store, "1")
+	require.NoError(t, err)
+	require.Equal(t, "mainnet", network.Label)
+
+	network, err = FindNetwork(store, "sepolia")
+	require.NoError(t, err)
+	require.Equal(t, "sepolia", network.Label)
+
+	_, err = FindNetwork(store, "11155111")
+	require.ErrorContains(t, err, "sepolia, sepolia-archive")
 
+	_, err = FindNetwork(store, "10")
+	require.ErrorIs(t, err, ErrNetworkNotFound)
+	_, err = FindNetwork(store, "missing")
+	require.ErrorIs(t, err, ErrNetworkNotFound)
 }
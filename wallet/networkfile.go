@@ -0,0 +1,257 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NetworkFileVersion is the version of the format written by ExportNetworks.
+const NetworkFileVersion = 1
+
+// networkFile is a YAML file of network definitions meant to be shared, for
+// example committed to a repository. Credentials are secret references, so
+// the file never holds a secret.
+type networkFile struct {
+	Version  int             `yaml:"version"`
+	Networks []sharedNetwork `yaml:"networks"`
+}
+
+type sharedNetwork struct {
+	Label            string            `yaml:"label"`
+	ChainId          int               `yaml:"chainId"`
+	Symbol           string            `yaml:"symbol"`
+	RpcUrl           string            `yaml:"rpcUrl"`
+	RpcUrls          []string          `yaml:"rpcUrls,omitempty"`
+	Headers          map[string]string `yaml:"headers,omitempty"`
+	BasicAuth        *BasicAuth        `yaml:"basicAuth,omitempty"`
+	BlockExplorerUrl string            `yaml:"blockExplorerUrl,omitempty"`
+}
+
+// ExportNetworks writes networks in the YAML format read by ParseNetworks.
+// The file is meant to be shared, so it fails on endpoint URLs holding a
+// password or an API key, which networks added before they were rejected
+// may have.
+func ExportNetworks(networks []Network) ([]byte, error) {
+	file := networkFile{Version: NetworkFileVersion, Networks: []sharedNetwork{}}
+	for _, network := range networks {
+		for _, endpoint := range network.Endpoints() {
+			err := validateUrlCredentials(endpoint)
+			if err != nil {
+				return nil, fmt.Errorf("network %s: %w, update it before exporting", network.Label, err)
+			}
+		}
+		file.Networks = append(file.Networks, sharedNetwork{
+			Label:            network.Label,
+			ChainId:          network.ChainId,
+			Symbol:           network.Symbol,
+			RpcUrl:           network.RpcUrl,
+			RpcUrls:          network.RpcUrls,
+			Headers:          network.Headers,
+			BasicAuth:        network.BasicAuth,
+			BlockExplorerUrl: network.BlockExplorerUrl,
+		})
+	}
+	return yaml.Marshal(file)
+}
+
+// ParseNetworks reads a file written by ExportNetworks and validates every
+// network like AddNetwork.
+func ParseNetworks(data []byte) ([]Network, error) {
+	var file networkFile
+	err := yaml.Unmarshal(data, &file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse networks: %v", err)
+	}
+	if file.Version > NetworkFileVersion {
+		return nil, fmt.Errorf("networks file version %d is newer than supported version %d", file.Version, NetworkFileVersion)
+	}
+
+	var networks []Network
+	labels := make(map[string]bool)
+	for _, shared := range file.Networks {
+		network := Network{
+			Label:            shared.Label,
+			ChainId:          shared.ChainId,
+			Symbol:           shared.Symbol,
+			RpcUrl:           shared.RpcUrl,
+			RpcUrls:          shared.RpcUrls,
+			Headers:          shared.Headers,
+			BasicAuth:        shared.BasicAuth,
+			BlockExplorerUrl: shared.BlockExplorerUrl,
+		}
+		if labels[network.Label] {
+			return nil, fmt.Errorf("networks file has duplicate label %q", network.Label)
+		}
+		labels[network.Label] = true
+		if network.ChainId <= 0 {
+			return nil, fmt.Errorf("network %s has no chain id", network.Label)
+		}
+		err := validateNetwork(network)
+		if err != nil {
+			return nil, fmt.Errorf("network %s: %w", network.Label, err)
+		}
+		networks = append(networks, network)
+	}
+	return networks, nil
+}
+
+// ImportConflict is the action of a network that would fail the import,
+// reported by dry runs.
+const ImportConflict = "conflict"
+
+// ImportOptions configure ImportNetworks.
+type ImportOptions struct {
+	// OnConflict is one of the Conflict constants, ConflictFail by default.
+	OnConflict string
+	// DryRun plans the import without writing anything. Conflicts that
+	// would fail the import are reported as ImportConflict.
+	DryRun bool
+}
+
+// ImportedNetwork describes what ImportNetworks did, or would do, with a
+// network of the file. Changes lists the fields that differ from the stored
+// network of the same label.
+type ImportedNetwork struct {
+	RestoredRecord
+	Changes []string
+}
+
+// networkFields returns the fields of a network as they are stored.
+func networkFields(network Network) map[string]string {
+	var fields map[string]json.RawMessage
+	data, _ := json.Marshal(network)
+	json.Unmarshal(data, &fields)
+
+	values := make(map[string]string)
+	for name, value := range fields {
+		values[name] = string(value)
+	}
+	return values
+}
+
+// diffNetworks lists the fields that differ between two networks as
+// "field: old -> new", sorted by field.
+func diffNetworks(existing, imported Network) []string {
+	before, after := networkFields(existing), networkFields(imported)
+
+	var names []string
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var changes []string
+	for _, name := range names {
+		if before[name] == after[name] || name == "label" {
+			continue
+		}
+		changes = append(changes, fmt.Sprintf("%s: %s -> %s", name, orNone(before[name]), orNone(after[name])))
+	}
+	return changes
+}
+
+func orNone(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
+
+// ImportNetworks adds the networks of a file to a store. Every conflict is
+// resolved before anything is written, and everything is written in a
+// single transaction, so a failing import leaves the store untouched. The
+// current selection is kept. Renamed networks get an -imported suffix.
+func ImportNetworks(store Store, networks []Network, options ImportOptions) ([]ImportedNetwork, error) {
+	var results []ImportedNetwork
+	err := store.Transaction(func(store Store) error {
+		var err error
+		results, err = importNetworks(store, networks, options)
+		return err
+	})
+	return results, err
+}
+
+func importNetworks(store Store, networks []Network, options ImportOptions) ([]ImportedNetwork, error) {
+	onConflict := options.OnConflict
+	if onConflict == "" {
+		onConflict = ConflictFail
+	}
+	err := validateConflict(onConflict)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := store.ListNetworks()
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]Network)
+	taken := make(map[string]bool)
+	selected := ""
+	for _, network := range stored {
+		if network.Selected {
+			selected = network.Label
+		}
+		network.Selected = false
+		existing[network.Label] = network
+		taken[network.Label] = true
+	}
+
+	var results []ImportedNetwork
+	var conflicts []string
+	for _, network := range networks {
+		current, exists := existing[network.Label]
+		network.Selected = false
+		record, err := planRecord(network.Label, exists, sameRecord(current, network), onConflict, "imported", taken)
+		if err != nil {
+			record.Action = ImportConflict
+			conflicts = append(conflicts, network.Label)
+		}
+		result := ImportedNetwork{RestoredRecord: record}
+		if exists {
+			result.Changes = diffNetworks(current, network)
+		}
+		results = append(results, result)
+	}
+	if options.DryRun {
+		return results, nil
+	}
+	if len(conflicts) > 0 {
+		return results, fmt.Errorf("%w with different content: %s", ErrNetworkExists, strings.Join(conflicts, ", "))
+	}
+
+	for i, result := range results {
+		network := networks[i]
+		network.Label = result.Label
+		switch result.Action {
+		case RestoreAdded, RestoreRenamed:
+			err = store.AddNetwork(network)
+		case RestoreOverwritten:
+			err = store.UpdateNetwork(network.Label, func(stored *Network) error {
+				*stored = network
+				return nil
+			})
+		}
+		if err != nil {
+			return results, fmt.Errorf("failed to import network %s: %w", result.BackupLabel, err)
+		}
+	}
+
+	// Adding networks selects them, so the selection is set back last
+	if selected != "" {
+		err = store.SelectNetwork(selected)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,304 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ERC-721 ABI for the ownership, metadata, enumeration and transfer functions
+const erc721ABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"tokenId","type":"uint256"}],"name":"ownerOf","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"tokenId","type":"uint256"}],"name":"tokenURI","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"owner","type":"address"},{"name":"index","type":"uint256"}],"name":"tokenOfOwnerByIndex","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"interfaceId","type":"bytes4"}],"name":"supportsInterface","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"constant":false,"inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"tokenId","type":"uint256"}],"name":"safeTransferFrom","outputs":[],"stateMutability":"nonpayable","type":"function"},
+	{"anonymous":false,"inputs":[{"indexed":true,"name":"from","type":"address"},{"indexed":true,"name":"to","type":"address"},{"indexed":true,"name":"tokenId","type":"uint256"}],"name":"Transfer","type":"event"}
+]`
+
+// logPageSize is the number of blocks scanned for Transfer logs at once, within
+// the range public endpoints allow for eth_getLogs.
+var logPageSize uint64 = 10000
+
+// ERC-165 interface id of the ERC-721 enumeration extension
+var erc721EnumerableInterfaceID = [4]byte{0x78, 0x0e, 0x9d, 0x63}
+
+type NFT struct {
+	Contract string
+	TokenId  *big.Int
+	Owner    string
+	TokenURI string
+}
+
+// nftCaller bundles a client and the parsed ERC-721 ABI for read-only calls.
+type nftCaller struct {
+	client   *ethclient.Client
+	abi      abi.ABI
+	contract common.Address
+}
+
+// withNFTCaller calls query with a caller for the contract on each endpoint of
+// the network in turn, as withEndpoints does.
+func withNFTCaller(contractAddress string, network Network, query func(caller *nftCaller) error) error {
+	parsedABI, err := abi.JSON(strings.NewReader(erc721ABI))
+	if err != nil {
+		return fmt.Errorf("failed to parse ERC-721 ABI: %v", err)
+	}
+
+	return withEndpoints(network, func(client *ethclient.Client) error {
+		return query(&nftCaller{
+			client:   client,
+			abi:      parsedABI,
+			contract: common.HexToAddress(contractAddress),
+		})
+	})
+}
+
+// call performs a read-only call of the given method and returns its single output.
+func (c *nftCaller) call(method string, args ...interface{}) (interface{}, error) {
+	callData, err := c.abi.Pack(method, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack data for %s call: %v", method, err)
+	}
+
+	result, err := c.client.CallContract(context.Background(), ethereum.CallMsg{
+		To:   &c.contract,
+		Data: callData,
+	}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call %s: %w", method, err)
+	}
+
+	values, err := c.abi.Unpack(method, result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unpack %s result: %v", method, err)
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("unexpected %s result", method)
+	}
+	return values[0], nil
+}
+
+func (c *nftCaller) ownerOf(tokenId *big.Int) (common.Address, error) {
+	owner, err := c.call("ownerOf", tokenId)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return owner.(common.Address), nil
+}
+
+// supportsEnumeration reports whether the contract implements the enumeration
+// extension. A contract that rejects supportsInterface does not.
+func (c *nftCaller) supportsEnumeration() (bool, error) {
+	supported, err := c.call("supportsInterface", erc721EnumerableInterfaceID)
+	if isNodeError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return supported.(bool), nil
+}
+
+// GetNFT returns the current owner and token URI of an ERC-721 token.
+// Contracts without the metadata extension are reported with an empty URI.
+func GetNFT(contractAddress string, tokenId *big.Int, network Network) (NFT, error) {
+	var nft NFT
+	err := withNFTCaller(contractAddress, network, func(caller *nftCaller) error {
+		owner, err := caller.ownerOf(tokenId)
+		if err != nil {
+			return err
+		}
+
+		nft = NFT{
+			Contract: caller.contract.String(),
+			TokenId:  tokenId,
+			Owner:    owner.String(),
+		}
+
+		uri, err := caller.call("tokenURI", tokenId)
+		if err == nil {
+			nft.TokenURI = uri.(string)
+		} else if !isNodeError(err) {
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return NFT{}, err
+	}
+
+	return nft, nil
+}
+
+// ListOwnedNFTs returns the ids of the ERC-721 tokens held by owner. Contracts
+// implementing the enumeration extension are queried through balanceOf and
+// tokenOfOwnerByIndex; for the rest the Transfer logs received by owner since
+// fromBlock are scanned in pages of logPageSize blocks and each candidate is
+// confirmed with ownerOf.
+func ListOwnedNFTs(contractAddress string, ownerAddress string, fromBlock uint64, network Network) ([]*big.Int, error) {
+	owner := common.HexToAddress(ownerAddress)
+
+	var ids []*big.Int
+	err := withNFTCaller(contractAddress, network, func(caller *nftCaller) error {
+		var err error
+		ids, err = listOwnedNFTs(caller, owner, fromBlock)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+func listOwnedNFTs(caller *nftCaller, owner common.Address, fromBlock uint64) ([]*big.Int, error) {
+	enumerable, err := caller.supportsEnumeration()
+	if err != nil {
+		return nil, err
+	}
+	if enumerable {
+		balance, err := caller.call("balanceOf", owner)
+		if err != nil {
+			return nil, err
+		}
+
+		count := balance.(*big.Int).Uint64()
+		ids := make([]*big.Int, 0, count)
+		for i := uint64(0); i < count; i++ {
+			id, err := caller.call("tokenOfOwnerByIndex", owner, new(big.Int).SetUint64(i))
+			if err != nil {
+				return nil, err
+			}
+			ids = append(ids, id.(*big.Int))
+		}
+		return ids, nil
+	}
+
+	// Fall back to scanning the Transfer events sent to the owner, a page of
+	// blocks at a time
+	head, err := caller.client.BlockNumber(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block number: %w", err)
+	}
+	var logs []types.Log
+	for start := fromBlock; start <= head; start += logPageSize {
+		end := start + logPageSize - 1
+		if end > head {
+			end = head
+		}
+		page, err := caller.client.FilterLogs(context.Background(), ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{caller.contract},
+			Topics: [][]common.Hash{
+				{caller.abi.Events["Transfer"].ID},
+				nil,
+				{common.BytesToHash(owner.Bytes())},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan Transfer logs of blocks %d to %d: %w", start, end, err)
+		}
+		logs = append(logs, page...)
+	}
+
+	seen := make(map[string]bool)
+	var ids []*big.Int
+	for _, entry := range logs {
+		if len(entry.Topics) != 4 {
+			// ERC-20 Transfer events share the signature but do not index the value
+			continue
+		}
+		id := entry.Topics[3].Big()
+		if seen[id.String()] {
+			continue
+		}
+		seen[id.String()] = true
+
+		current, err := caller.ownerOf(id)
+		if err != nil {
+			return nil, err
+		}
+		if current == owner {
+			ids = append(ids, id)
+		}
+	}
+
+	sort.Slice(ids, func(i, j int) bool { return ids[i].Cmp(ids[j]) < 0 })
+	return ids, nil
+}
+
+// SendNFT transfers an ERC-721 token with safeTransferFrom. The transfer is
+// simulated first so that a recipient contract which does not accept ERC-721
+// tokens is reported before anything is broadcast.
+func SendNFT(fromPrivateKey string, contractAddress string, toAddress string, tokenId *big.Int, network Network) (Transaction, error) {
+	privateKey, err := parsePrivateKey(fromPrivateKey)
+	if err != nil {
+		return Transaction{}, err
+	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	to := common.HexToAddress(toAddress)
+
+	// The checks fail over to other endpoints, the transfer itself is signed
+	// once and only its broadcast is retried
+	var owner common.Address
+	var callData []byte
+	var simulateErr error
+	var recipientCode []byte
+	err = withNFTCaller(contractAddress, network, func(caller *nftCaller) error {
+		var err error
+		owner, err = caller.ownerOf(tokenId)
+		if err != nil || owner != fromAddress {
+			return err
+		}
+
+		callData, err = caller.abi.Pack("safeTransferFrom", fromAddress, to, tokenId)
+		if err != nil {
+			return fmt.Errorf("failed to pack data for safeTransferFrom call: %v", err)
+		}
+
+		_, err = caller.client.CallContract(context.Background(), ethereum.CallMsg{
+			From: fromAddress,
+			To:   &caller.contract,
+			Data: callData,
+		}, nil)
+		if err != nil && !isNodeError(err) {
+			return err
+		}
+		simulateErr = err
+		if err != nil {
+			recipientCode, _ = caller.client.CodeAt(context.Background(), to, nil)
+		}
+		return nil
+	})
+	if err != nil {
+		return Transaction{}, err
+	}
+	if owner != fromAddress {
+		return Transaction{}, fmt.Errorf("token %s is owned by %s, not %s", tokenId, owner.String(), fromAddress.String())
+	}
+	if simulateErr != nil {
+		if len(recipientCode) > 0 {
+			return Transaction{}, fmt.Errorf("recipient %s is a contract that does not accept ERC-721 tokens (onERC721Received missing or rejected): %v", to.String(), simulateErr)
+		}
+		return Transaction{}, fmt.Errorf("safeTransferFrom would revert: %v", simulateErr)
+	}
+
+	client, err := dialNetwork(network)
+	if err != nil {
+		return Transaction{}, err
+	}
+	defer client.Close()
+
+	return sendContractTx(client, privateKey, common.HexToAddress(contractAddress), callData, network)
+}
@@ -0,0 +1,149 @@
+package wallet
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+func TestERC721ABI(t *testing.T) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc721ABI))
+	require.NoError(t, err)
+
+	transfer := parsedABI.Events["Transfer"]
+	require.Equal(t, crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)")), transfer.ID)
+
+	safeTransfer := parsedABI.Methods["safeTransferFrom"]
+	require.Equal(t, crypto.Keccak256([]byte("safeTransferFrom(address,address,uint256)"))[:4], safeTransfer.ID)
+}
+
+// newNFTServer answers the JSON-RPC calls of the ERC-721 functions: eth_call
+// is answered by call with the method of the ABI it invokes, returning either
+// a result or a revert message, and other methods by results.
+func newNFTServer(t *testing.T, call func(method string) (string, string), results func(method string, params []json.RawMessage) string) *httptest.Server {
+	parsedABI, err := abi.JSON(strings.NewReader(erc721ABI))
+	require.NoError(t, err)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		w.Header().Set("Content-Type", "application/json")
+
+		result, revert := "", ""
+		if request.Method == "eth_call" {
+			var arg struct {
+				Input hexutil.Bytes `json:"input"`
+			}
+			require.NoError(t, json.Unmarshal(request.Params[0], &arg))
+			method, err := parsedABI.MethodById(arg.Input[:4])
+			require.NoError(t, err)
+			result, revert = call(method.Name)
+		} else {
+			result = results(request.Method, request.Params)
+		}
+
+		switch {
+		case revert != "":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":3,"message":"execution reverted: %s"}}`, request.ID, revert)
+		case result == "":
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32601,"message":"method not found"}}`, request.ID)
+		default:
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":%s}`, request.ID, result)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// word encodes a value as a 32 byte ABI word in JSON.
+func word(value []byte) string {
+	return `"` + hexutil.Encode(common.LeftPadBytes(value, 32)) + `"`
+}
+
+func TestListOwnedNFTsScansLogsInPages(t *testing.T) {
+	defer func(size uint64) { logPageSize = size }(logPageSize)
+	logPageSize = 100
+
+	owner := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	contract := "0x5FbDB2315678afecb367f032d93F642f64180aa3"
+	transfer := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+	var pages [][2]string
+	server := newNFTServer(t, func(method string) (string, string) {
+		switch method {
+		case "supportsInterface":
+			return word(nil), ""
+		case "ownerOf":
+			return word(owner.Bytes()), ""
+		}
+		return "", "unexpected call"
+	}, func(method string, params []json.RawMessage) string {
+		switch method {
+		case "eth_chainId":
+			return `"0x1"`
+		case "eth_blockNumber":
+			return `"0xfa"`
+		case "eth_getLogs":
+			var filter struct {
+				FromBlock string `json:"fromBlock"`
+				ToBlock   string `json:"toBlock"`
+			}
+			require.NoError(t, json.Unmarshal(params[0], &filter))
+			pages = append(pages, [2]string{filter.FromBlock, filter.ToBlock})
+			if filter.FromBlock != "0x96" {
+				return `[]`
+			}
+			topics := []string{transfer.Hex(), common.Hash{}.Hex(), common.BytesToHash(owner.Bytes()).Hex(), common.BigToHash(big.NewInt(7)).Hex()}
+			return `[{"address":"` + contract + `","topics":["` + strings.Join(topics, `","`) + `"],"data":"0x","blockNumber":"0x70","transactionHash":"` + common.Hash{}.Hex() + `","transactionIndex":"0x0","blockHash":"` + common.Hash{}.Hex() + `","logIndex":"0x0","removed":false}]`
+		}
+		return ""
+	})
+
+	ids, err := ListOwnedNFTs(contract, owner.Hex(), 50, Network{ChainId: 1, RpcUrl: server.URL})
+	require.NoError(t, err)
+	require.Equal(t, []*big.Int{big.NewInt(7)}, ids)
+	require.Equal(t, [][2]string{{"0x32", "0x95"}, {"0x96", "0xf9"}, {"0xfa", "0xfa"}}, pages)
+}
+
+func TestSendNFTToContractWithoutReceiver(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+
+	server := newNFTServer(t, func(method string) (string, string) {
+		switch method {
+		case "ownerOf":
+			return word(from.Bytes()), ""
+		case "safeTransferFrom":
+			return "", "ERC721: transfer to non ERC721Receiver implementer"
+		}
+		return "", "unexpected call"
+	}, func(method string, params []json.RawMessage) string {
+		switch method {
+		case "eth_chainId":
+			return `"0x1"`
+		case "eth_getCode":
+			return `"0x6080"`
+		}
+		return ""
+	})
+
+	network := Network{ChainId: 1, RpcUrl: server.URL}
+	_, err = SendNFT(hex.EncodeToString(crypto.FromECDSA(key)), "0x5FbDB2315678afecb367f032d93F642f64180aa3", "0x3C44CdDdB6a900fa2b585dd299e03d12FA4293BC", big.NewInt(7), network)
+	require.ErrorContains(t, err, "does not accept ERC-721 tokens")
+}
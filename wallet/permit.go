@@ -0,0 +1,208 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// EIP-2612 ABI for the functions needed to build a permit
+const erc2612ABI = `[
+	{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[],"name":"version","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"nonces","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"constant":true,"inputs":[],"name":"DOMAIN_SEPARATOR","outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}
+]`
+
+// Permit is a signed EIP-2612 approval, ready to be submitted by a relayer.
+type Permit struct {
+	Token     string
+	Owner     string
+	Spender   string
+	Value     *big.Int
+	Nonce     *big.Int
+	Deadline  *big.Int
+	V         uint8
+	R         string
+	S         string
+	Signature string
+	TypedData apitypes.TypedData
+}
+
+// PermitTypedData builds the EIP-712 typed data of an EIP-2612 Permit.
+func PermitTypedData(name, version string, chainId int, token, owner, spender string, value, nonce, deadline *big.Int) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: apitypes.TypedDataDomain{
+			Name:              name,
+			Version:           version,
+			ChainId:           math.NewHexOrDecimal256(int64(chainId)),
+			VerifyingContract: common.HexToAddress(token).String(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"owner":    common.HexToAddress(owner).String(),
+			"spender":  common.HexToAddress(spender).String(),
+			"value":    value.String(),
+			"nonce":    nonce.String(),
+			"deadline": deadline.String(),
+		},
+	}
+}
+
+// SignTypedData signs EIP-712 typed data and returns the 65 byte signature
+// with v in the 27/28 form expected by Solidity's ecrecover.
+func SignTypedData(privateKey string, typedData apitypes.TypedData) ([]byte, error) {
+	key, err := parsePrivateKey(privateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+
+	signature, err := crypto.Sign(hash, key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign typed data: %v", err)
+	}
+	signature[crypto.RecoveryIDOffset] += 27
+
+	return signature, nil
+}
+
+// SignPermit reads the token's name, version, nonce of the owner and domain
+// separator, builds the EIP-2612 Permit and signs it with the owner's key.
+// Tokens without a version() function are assumed to use version "1"; the
+// locally computed domain separator must match DOMAIN_SEPARATOR on-chain.
+func SignPermit(ownerPrivateKey string, tokenAddress string, spenderAddress string, value *big.Int, deadline *big.Int, network Network) (Permit, error) {
+	key, err := parsePrivateKey(ownerPrivateKey)
+	if err != nil {
+		return Permit{}, err
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	token := common.HexToAddress(tokenAddress)
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc2612ABI))
+	if err != nil {
+		return Permit{}, fmt.Errorf("failed to parse EIP-2612 ABI: %v", err)
+	}
+
+	// The token is read through any endpoint on the chain of the network,
+	// whose id is part of the signed domain
+	var name, version string
+	var nonce *big.Int
+	var domainSeparator [32]byte
+	unsupported := false
+	err = withEndpoints(network, func(client *ethclient.Client) error {
+		call := func(method string, args ...interface{}) (interface{}, error) {
+			callData, err := parsedABI.Pack(method, args...)
+			if err != nil {
+				return nil, fmt.Errorf("failed to pack data for %s call: %v", method, err)
+			}
+			result, err := client.CallContract(context.Background(), ethereum.CallMsg{
+				To:   &token,
+				Data: callData,
+			}, nil)
+			if err != nil {
+				return nil, fmt.Errorf("failed to call %s: %w", method, err)
+			}
+			values, err := parsedABI.Unpack(method, result)
+			if err != nil || len(values) != 1 {
+				return nil, fmt.Errorf("failed to unpack %s result: %v", method, err)
+			}
+			return values[0], nil
+		}
+
+		result, err := call("name")
+		if err != nil {
+			return err
+		}
+		name = result.(string)
+
+		version = "1"
+		result, err = call("version")
+		if err == nil {
+			version = result.(string)
+		} else if !isNodeError(err) {
+			return err
+		}
+
+		result, err = call("nonces", owner)
+		if err != nil {
+			unsupported = isNodeError(err)
+			return err
+		}
+		nonce = result.(*big.Int)
+
+		result, err = call("DOMAIN_SEPARATOR")
+		if err != nil {
+			unsupported = isNodeError(err)
+			return err
+		}
+		domainSeparator = result.([32]byte)
+		return nil
+	})
+	if err != nil && unsupported {
+		return Permit{}, fmt.Errorf("token does not support EIP-2612: %w", err)
+	}
+	if err != nil {
+		return Permit{}, err
+	}
+
+	typedData := PermitTypedData(name, version, network.ChainId, token.String(), owner.String(), spenderAddress, value, nonce, deadline)
+
+	expected := domainSeparator
+	computed, err := typedData.HashStruct("EIP712Domain", typedData.Domain.Map())
+	if err != nil {
+		return Permit{}, fmt.Errorf("failed to hash domain: %v", err)
+	}
+	if !bytes.Equal(computed, expected[:]) {
+		return Permit{}, fmt.Errorf("domain separator mismatch: token reports %s, computed %s (check the chain id and token version)", hexutil.Encode(expected[:]), computed)
+	}
+
+	signature, err := SignTypedData(ownerPrivateKey, typedData)
+	if err != nil {
+		return Permit{}, err
+	}
+
+	return Permit{
+		Token:     token.String(),
+		Owner:     owner.String(),
+		Spender:   common.HexToAddress(spenderAddress).String(),
+		Value:     value,
+		Nonce:     nonce,
+		Deadline:  deadline,
+		V:         signature[64],
+		R:         hexutil.Encode(signature[:32]),
+		S:         hexutil.Encode(signature[32:64]),
+		Signature: hexutil.Encode(signature),
+		TypedData: typedData,
+	}, nil
+}
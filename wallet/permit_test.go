@@ -0,0 +1,42 @@
+package wallet
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPermitTypeHash(t *testing.T) {
+	typedData := PermitTypedData("Token", "1", 1, "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		big.NewInt(1000), big.NewInt(0), big.NewInt(1700000000))
+
+	expected := crypto.Keccak256([]byte("Permit(address owner,address spender,uint256 value,uint256 nonce,uint256 deadline)"))
+	require.Equal(t, expected, []byte(typedData.TypeHash("Permit")))
+}
+
+func TestSignTypedDataRecoversOwner(t *testing.T) {
+	owner := "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"
+	privateKey := "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+	typedData := PermitTypedData("Token", "1", 31337, "0x5FbDB2315678afecb367f032d93F642f64180aa3",
+		owner, "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
+		big.NewInt(1000), big.NewInt(3), big.NewInt(1700000000))
+
+	signature, err := SignTypedData(privateKey, typedData)
+	require.NoError(t, err)
+	require.Len(t, signature, 65)
+	require.Contains(t, []byte{27, 28}, signature[64])
+
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	require.NoError(t, err)
+
+	recoverable := append([]byte{}, signature...)
+	recoverable[64] -= 27
+	pub, err := crypto.SigToPub(hash, recoverable)
+	require.NoError(t, err)
+	require.Equal(t, owner, crypto.PubkeyToAddress(*pub).String())
+}
@@ -0,0 +1,84 @@
+package wallet
+
+import "sort"
+
+// presets are well known networks with public endpoints that need no API
+// key. Networks added from a preset are ordinary records that can be
+// updated like any other.
+var presets = map[string]Network{
+	"mainnet": {
+		ChainId:          1,
+		Symbol:           "ETH",
+		RpcUrl:           "https://ethereum-rpc.publicnode.com",
+		RpcUrls:          []string{"https://cloudflare-eth.com"},
+		BlockExplorerUrl: "https://etherscan.io",
+	},
+	"sepolia": {
+		ChainId:          11155111,
+		Symbol:           "ETH",
+		RpcUrl:           "https://ethereum-sepolia-rpc.publicnode.com",
+		RpcUrls:          []string{"https://rpc.sepolia.org"},
+		BlockExplorerUrl: "https://sepolia.etherscan.io",
+	},
+	"holesky": {
+		ChainId:          17000,
+		Symbol:           "ETH",
+		RpcUrl:           "https://ethereum-holesky-rpc.publicnode.com",
+		BlockExplorerUrl: "https://holesky.etherscan.io",
+	},
+	"arbitrum": {
+		ChainId:          42161,
+		Symbol:           "ETH",
+		RpcUrl:           "https://arb1.arbitrum.io/rpc",
+		RpcUrls:          []string{"https://arbitrum-one-rpc.publicnode.com"},
+		BlockExplorerUrl: "https://arbiscan.io",
+	},
+	"optimism": {
+		ChainId:          10,
+		Symbol:           "ETH",
+		RpcUrl:           "https://mainnet.optimism.io",
+		RpcUrls:          []string{"https://optimism-rpc.publicnode.com"},
+		BlockExplorerUrl: "https://optimistic.etherscan.io",
+	},
+	"base": {
+		ChainId:          8453,
+		Symbol:           "ETH",
+		RpcUrl:           "https://mainnet.base.org",
+		RpcUrls:          []string{"https://base-rpc.publicnode.com"},
+		BlockExplorerUrl: "https://basescan.org",
+	},
+	"polygon": {
+		ChainId:          137,
+		Symbol:           "POL",
+		RpcUrl:           "https://polygon-rpc.com",
+		RpcUrls:          []string{"https://polygon-bor-rpc.publicnode.com"},
+		BlockExplorerUrl: "https://polygonscan.com",
+	},
+}
+
+// Preset returns the preset network called name, labelled with its name.
+func Preset(name string) (Network, bool) {
+	network, ok := presets[name]
+	if !ok {
+		return Network{}, false
+	}
+	network.Label = name
+	network.RpcUrls = append([]string{}, network.RpcUrls...)
+	return network, true
+}
+
+// Presets returns every preset network, sorted by name.
+func Presets() []Network {
+	names := make([]string, 0, len(presets))
+	for name := range presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	networks := make([]Network, 0, len(names))
+	for _, name := range names {
+		network, _ := Preset(name)
+		networks = append(networks, network)
+	}
+	return networks
+}
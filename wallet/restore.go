@@ -0,0 +1,321 @@
+package wallet
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// How RestoreBackup handles a record whose label exists with other content.
+const (
+	ConflictFail      = "fail"
+	ConflictSkip      = "skip"
+	ConflictOverwrite = "overwrite"
+	ConflictRename    = "rename"
+)
+
+// What RestoreBackup did with a record.
+const (
+	RestoreAdded       = "added"
+	RestoreUnchanged   = "unchanged"
+	RestoreSkipped     = "skipped"
+	RestoreOverwritten = "overwritten"
+	RestoreRenamed     = "renamed"
+)
+
+type RestoreOptions struct {
+	// Merge restores into a wallet that already has accounts or networks.
+	Merge bool
+	// OnConflict is one of the Conflict constants, ConflictFail by default.
+	OnConflict string
+}
+
+// RestoredRecord describes what happened to an account or network of a
+// backup. Label differs from BackupLabel when the record was renamed.
+type RestoredRecord struct {
+	BackupLabel string
+	Label       string
+	Action      string
+}
+
+type RestoreResult struct {
+	Accounts         []RestoredRecord
+	Networks         []RestoredRecord
+	ArchivedAccounts []RestoredRecord
+	ArchivedNetworks []RestoredRecord
+}
+
+func validateConflict(onConflict string) error {
+	switch onConflict {
+	case ConflictFail, ConflictSkip, ConflictOverwrite, ConflictRename:
+		return nil
+	}
+	return fmt.Errorf("unknown conflict handling %q, expected fail, skip, overwrite or rename", onConflict)
+}
+
+func sameRecord(a, b interface{}) bool {
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
+
+// freeLabel returns label with a suffix such as -restored that is not taken.
+func freeLabel(label string, suffix string, taken map[string]bool) string {
+	candidate := label + "-" + suffix
+	for i := 2; taken[candidate]; i++ {
+		candidate = fmt.Sprintf("%s-%s-%d", label, suffix, i)
+	}
+	return candidate
+}
+
+// planRecord decides what to do with a record of a backup or import given
+// whether its label exists and with the same content. Renamed labels get
+// suffix.
+func planRecord(label string, exists, same bool, onConflict string, suffix string, taken map[string]bool) (RestoredRecord, error) {
+	record := RestoredRecord{BackupLabel: label, Label: label}
+	switch {
+	case !exists:
+		record.Action = RestoreAdded
+	case same:
+		record.Action = RestoreUnchanged
+	case onConflict == ConflictSkip:
+		record.Action = RestoreSkipped
+	case onConflict == ConflictOverwrite:
+		record.Action = RestoreOverwritten
+	case onConflict == ConflictRename:
+		record.Action = RestoreRenamed
+		record.Label = freeLabel(label, suffix, taken)
+	default:
+		return record, fmt.Errorf("%s", label)
+	}
+	taken[record.Label] = true
+	return record, nil
+}
+
+// planNetworks plans the restore of the networks of a backup over the
+// existing ones, appending conflicts. It returns the new label of every
+// network.
+func planNetworks(networks []Network, existing []Network, onConflict string, conflicts *[]string) ([]RestoredRecord, map[string]string) {
+	existingNetworks := make(map[string]Network)
+	taken := make(map[string]bool)
+	for _, network := range existing {
+		existingNetworks[network.Label] = network
+		taken[network.Label] = true
+	}
+
+	var records []RestoredRecord
+	renamed := make(map[string]string)
+	for _, network := range networks {
+		existing, exists := existingNetworks[network.Label]
+		existing.Selected, network.Selected = false, false
+		record, err := planRecord(network.Label, exists, sameRecord(existing, network), onConflict, "restored", taken)
+		if err != nil {
+			*conflicts = append(*conflicts, "network "+network.Label)
+		}
+		renamed[network.Label] = record.Label
+		records = append(records, record)
+	}
+	return records, renamed
+}
+
+// planAccounts is planNetworks for accounts. The contracts accounts track
+// are keyed by network, so they follow the renamed networks.
+func planAccounts(accounts []Account, existing []Account, onConflict string, renamedNetworks map[string]string, conflicts *[]string) ([]RestoredRecord, map[string]string) {
+	existingAccounts := make(map[string]Account)
+	taken := make(map[string]bool)
+	for _, account := range existing {
+		existingAccounts[account.Label] = account
+		taken[account.Label] = true
+	}
+
+	var records []RestoredRecord
+	renamed := make(map[string]string)
+	for i, account := range accounts {
+		if len(account.MultiTokens) > 0 {
+			multiTokens := make(map[string][]string)
+			for network, contracts := range account.MultiTokens {
+				if label, ok := renamedNetworks[network]; ok {
+					network = label
+				}
+				multiTokens[network] = contracts
+			}
+			account.MultiTokens = multiTokens
+			accounts[i] = account
+		}
+
+		existing, exists := existingAccounts[account.Label]
+		existing.Selected, account.Selected = false, false
+		record, err := planRecord(account.Label, exists, sameRecord(existing, account), onConflict, "restored", taken)
+		if err != nil {
+			*conflicts = append(*conflicts, "account "+account.Label)
+		}
+		renamed[account.Label] = record.Label
+		records = append(records, record)
+	}
+	return records, renamed
+}
+
+// RestoreBackup writes the accounts and networks of a backup to a store,
+// archived ones to the archive. The backup is validated and every conflict
+// is resolved before anything is written, and everything is written in a
+// single transaction, so a failing restore leaves the store untouched.
+// Without Merge the store must be empty and the selection of the backup is
+// restored; with Merge the current selection is kept.
+func RestoreBackup(store Store, backup Backup, options RestoreOptions) (RestoreResult, error) {
+	var result RestoreResult
+	err := store.Transaction(func(store Store) error {
+		var err error
+		result, err = restoreBackup(store, backup, options)
+		return err
+	})
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	return result, nil
+}
+
+func restoreBackup(store Store, backup Backup, options RestoreOptions) (RestoreResult, error) {
+	var result RestoreResult
+
+	err := backup.Validate()
+	if err != nil {
+		return result, err
+	}
+	onConflict := options.OnConflict
+	if onConflict == "" {
+		onConflict = ConflictFail
+	}
+	err = validateConflict(onConflict)
+	if err != nil {
+		return result, err
+	}
+
+	accounts, err := store.ListAccounts()
+	if err != nil {
+		return result, err
+	}
+	networks, err := store.ListNetworks()
+	if err != nil {
+		return result, err
+	}
+	archivedAccounts, err := store.ListArchivedAccounts()
+	if err != nil {
+		return result, err
+	}
+	archivedNetworks, err := store.ListArchivedNetworks()
+	if err != nil {
+		return result, err
+	}
+	if !options.Merge && (len(accounts) > 0 || len(networks) > 0 || len(archivedAccounts) > 0 || len(archivedNetworks) > 0) {
+		return result, fmt.Errorf("the wallet already has accounts or networks, restore with merge to combine them")
+	}
+
+	selectedNetwork := ""
+	for _, network := range networks {
+		if network.Selected {
+			selectedNetwork = network.Label
+		}
+	}
+	selectedAccount := ""
+	for _, account := range accounts {
+		if account.Selected {
+			selectedAccount = account.Label
+		}
+	}
+
+	// Archived records are planned against the archive, apart from the
+	// records in use
+	var conflicts []string
+	var renamedNetworks, renamedAccounts, renamedArchivedNetworks map[string]string
+	result.Networks, renamedNetworks = planNetworks(backup.Networks, networks, onConflict, &conflicts)
+	result.ArchivedNetworks, renamedArchivedNetworks = planNetworks(backup.ArchivedNetworks, archivedNetworks, onConflict, &conflicts)
+	result.Accounts, renamedAccounts = planAccounts(backup.Accounts, accounts, onConflict, renamedNetworks, &conflicts)
+	for label, renamed := range renamedNetworks {
+		renamedArchivedNetworks[label] = renamed
+	}
+	result.ArchivedAccounts, _ = planAccounts(backup.ArchivedAccounts, archivedAccounts, onConflict, renamedArchivedNetworks, &conflicts)
+
+	if len(conflicts) > 0 {
+		return RestoreResult{}, fmt.Errorf("labels exist with different content: %s", strings.Join(conflicts, ", "))
+	}
+
+	for i, record := range result.Networks {
+		network := backup.Networks[i]
+		network.Label = record.Label
+		switch record.Action {
+		case RestoreAdded, RestoreRenamed:
+			err = store.AddNetwork(network)
+		case RestoreOverwritten:
+			err = store.UpdateNetwork(network.Label, func(stored *Network) error {
+				*stored = network
+				return nil
+			})
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to restore network %s: %w", record.BackupLabel, err)
+		}
+	}
+
+	for i, record := range result.ArchivedNetworks {
+		network := backup.ArchivedNetworks[i]
+		network.Label = record.Label
+		switch record.Action {
+		case RestoreAdded, RestoreRenamed, RestoreOverwritten:
+			err = store.PutArchivedNetwork(network)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to restore archived network %s: %w", record.BackupLabel, err)
+		}
+	}
+
+	for i, record := range result.Accounts {
+		account := backup.Accounts[i]
+		account.Label = record.Label
+		switch record.Action {
+		case RestoreAdded, RestoreRenamed:
+			err = store.ImportAccount(account)
+		case RestoreOverwritten:
+			err = store.UpdateAccount(account.Label, func(stored *Account) error {
+				*stored = account
+				return nil
+			})
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to restore account %s: %w", record.BackupLabel, err)
+		}
+	}
+
+	for i, record := range result.ArchivedAccounts {
+		account := backup.ArchivedAccounts[i]
+		account.Label = record.Label
+		switch record.Action {
+		case RestoreAdded, RestoreRenamed, RestoreOverwritten:
+			err = store.PutArchivedAccount(account)
+		}
+		if err != nil {
+			return result, fmt.Errorf("failed to restore archived account %s: %w", record.BackupLabel, err)
+		}
+	}
+
+	// Adding records selects them, so the selection is set last
+	if selectedNetwork == "" && backup.SelectedNetwork != "" {
+		selectedNetwork = renamedNetworks[backup.SelectedNetwork]
+	}
+	if selectedNetwork != "" {
+		err = store.SelectNetwork(selectedNetwork)
+		if err != nil {
+			return result, err
+		}
+	}
+	if selectedAccount == "" && backup.SelectedAccount != "" {
+		selectedAccount = renamedAccounts[backup.SelectedAccount]
+	}
+	if selectedAccount != "" {
+		err = store.SelectAccount(selectedAccount)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
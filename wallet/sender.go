@@ -0,0 +1,201 @@
+package wallet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// Sender signs and broadcasts a series of payments from one account over a
+// single connection. Nonces are chosen by the caller, which lets a batch
+// assign them locally instead of asking the node before every transaction.
+type Sender struct {
+	client     *ethclient.Client
+	privateKey *ecdsa.PrivateKey
+	from       common.Address
+	network    Network
+	erc20      abi.ABI
+}
+
+func NewSender(fromPrivateKey string, network Network) (*Sender, error) {
+	privateKey, err := parsePrivateKey(fromPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ERC-20 ABI: %v", err)
+	}
+
+	client, err := dialNetwork(network)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Sender{
+		client:     client,
+		privateKey: privateKey,
+		from:       crypto.PubkeyToAddress(privateKey.PublicKey),
+		network:    network,
+		erc20:      parsedABI,
+	}, nil
+}
+
+func (s *Sender) Close() {
+	s.client.Close()
+}
+
+// Address returns the address payments are sent from.
+func (s *Sender) Address() string {
+	return s.from.String()
+}
+
+// PendingNonce returns the next nonce of the account, including transactions still in the pool.
+func (s *Sender) PendingNonce() (uint64, error) {
+	nonce, err := s.client.PendingNonceAt(context.Background(), s.from)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %v", err)
+	}
+	return nonce, nil
+}
+
+// ConfirmedNonce returns the next nonce of the account at the latest block.
+func (s *Sender) ConfirmedNonce() (uint64, error) {
+	nonce, err := s.client.NonceAt(context.Background(), s.from, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get nonce: %v", err)
+	}
+	return nonce, nil
+}
+
+// GasPrice returns the gas price suggested by the node.
+func (s *Sender) GasPrice() (*big.Int, error) {
+	gasPrice, err := s.client.SuggestGasPrice(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %v", err)
+	}
+	return gasPrice, nil
+}
+
+// paymentCall returns the recipient, value and data of the transaction of a
+// payment: the payee for the native currency, the token contract otherwise.
+func (s *Sender) paymentCall(payment Payment) (common.Address, *big.Int, []byte, error) {
+	to := common.HexToAddress(payment.Address)
+	if payment.IsNative() {
+		return to, payment.Amount, nil, nil
+	}
+
+	data, err := s.erc20.Pack("transfer", to, payment.Amount)
+	if err != nil {
+		return common.Address{}, nil, nil, fmt.Errorf("failed to pack data for transfer call: %v", err)
+	}
+	return common.HexToAddress(payment.Asset), big.NewInt(0), data, nil
+}
+
+// EstimateGas estimates the gas limit of the transaction of a payment.
+func (s *Sender) EstimateGas(payment Payment) (uint64, error) {
+	to, value, data, err := s.paymentCall(payment)
+	if err != nil {
+		return 0, err
+	}
+
+	gasLimit, err := s.client.EstimateGas(context.Background(), ethereum.CallMsg{
+		From:  s.from,
+		To:    &to,
+		Value: value,
+		Data:  data,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas: %w", nodeError(err))
+	}
+	return gasLimit, nil
+}
+
+// SignPayment builds and signs the transaction of a payment with the given nonce.
+func (s *Sender) SignPayment(payment Payment, nonce uint64) (*types.Transaction, error) {
+	gasPrice, err := s.GasPrice()
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit, err := s.EstimateGas(payment)
+	if err != nil {
+		return nil, err
+	}
+
+	to, value, data, err := s.paymentCall(payment)
+	if err != nil {
+		return nil, err
+	}
+	tx := types.NewTransaction(nonce, to, value, gasLimit, gasPrice, data)
+
+	return signTx(tx, s.privateKey, s.network)
+}
+
+// Broadcast sends a signed transaction to the network.
+func (s *Sender) Broadcast(tx *types.Transaction) error {
+	err := sendTransaction(s.client, s.network, tx)
+	if err != nil {
+		return fmt.Errorf("failed to send transaction: %w", err)
+	}
+	return nil
+}
+
+// Receipt returns the receipt of a transaction, or nil if it has not been mined.
+func (s *Sender) Receipt(hash string) (*types.Receipt, error) {
+	receipt, err := s.client.TransactionReceipt(context.Background(), common.HexToHash(hash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction receipt: %v", err)
+	}
+	return receipt, nil
+}
+
+// Known reports whether the node knows the transaction, either mined or still in the pool.
+func (s *Sender) Known(hash string) (bool, error) {
+	_, _, err := s.client.TransactionByHash(context.Background(), common.HexToHash(hash))
+	if errors.Is(err, ethereum.NotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get transaction: %v", err)
+	}
+	return true, nil
+}
+
+// WaitForReceipt blocks until the transaction with the given nonce has been
+// mined. It fails with ErrNonceUsed once another transaction of the account
+// was mined with that nonce, as the transaction can then never be mined.
+func (s *Sender) WaitForReceipt(hash string, nonce uint64) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := onNewBlock(context.Background(), s.client, func() (bool, error) {
+		confirmed, err := s.ConfirmedNonce()
+		if err != nil {
+			return false, err
+		}
+		// The receipt is read after the nonce, so a transaction mined in
+		// between is not taken for a replaced one
+		receipt, err = s.Receipt(hash)
+		if err != nil || receipt != nil {
+			return receipt != nil, err
+		}
+		if confirmed > nonce {
+			return false, fmt.Errorf("%w: nonce %d", ErrNonceUsed, nonce)
+		}
+		return false, nil
+	})
+	return receipt, err
+}
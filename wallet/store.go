@@ -0,0 +1,59 @@
+package wallet
+
+// Store persists the accounts and networks of a wallet and which of each is
+// selected. Every method is atomic: selecting, importing and selecting, or
+// deleting and reselecting happen as a single change, so a crash can never
+// leave two accounts selected or a selection pointing at nothing.
+//
+// Selected is not stored with accounts and networks, it is set on the values
+// a Store returns from the current selection.
+type Store interface {
+	GetAccount(label string) (Account, error)
+	ListAccounts() ([]Account, error)
+	// ImportAccount adds a new account and selects it.
+	ImportAccount(account Account) error
+	// UpdateAccount applies update to an existing account. The label cannot
+	// be changed.
+	UpdateAccount(label string, update func(*Account) error) error
+	// RemoveAccount deletes an account. If it was selected the first
+	// remaining account is selected instead.
+	RemoveAccount(label string) error
+	// ArchiveAccount moves an account out of the wallet into the archive,
+	// reselecting like RemoveAccount, and UnarchiveAccount moves it back
+	// without selecting it.
+	ArchiveAccount(label string) error
+	UnarchiveAccount(label string) error
+	// PutArchivedAccount writes an account to the archive, replacing an
+	// archived account with the same label. It is used to restore backups.
+	PutArchivedAccount(account Account) error
+	ListArchivedAccounts() ([]Account, error)
+	SelectAccount(label string) error
+	GetSelectedAccount() (Account, error)
+
+	GetNetwork(label string) (Network, error)
+	ListNetworks() ([]Network, error)
+	// AddNetwork adds a new network and selects it.
+	AddNetwork(network Network) error
+	// UpdateNetwork applies update to an existing network. A new label
+	// renames the network, keeping its selection and moving the ERC-1155
+	// contracts accounts track on it to the new label.
+	UpdateNetwork(label string, update func(*Network) error) error
+	// DeleteNetwork deletes a network. If it was selected the first
+	// remaining network is selected instead.
+	DeleteNetwork(label string) error
+	// ArchiveNetwork and UnarchiveNetwork are the network counterparts of
+	// ArchiveAccount and UnarchiveAccount.
+	ArchiveNetwork(label string) error
+	UnarchiveNetwork(label string) error
+	PutArchivedNetwork(network Network) error
+	ListArchivedNetworks() ([]Network, error)
+	SelectNetwork(label string) error
+	GetSelectedNetwork() (Network, error)
+
+	// Transaction runs update with a store on which every change is part of
+	// a single atomic change: if update returns an error nothing it did is
+	// kept. The store passed to update must not be used after it returns.
+	Transaction(update func(store Store) error) error
+
+	Close() error
+}
@@ -0,0 +1,218 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestBoltStore(t *testing.T) *BoltStore {
+	store, err := OpenBoltStore(filepath.Join(t.TempDir(), "store.db"))
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// forEachStore runs test against every Store implementation.
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	stores := map[string]func(t *testing.T) Store{
+		"bolt":   func(t *testing.T) Store { return newTestBoltStore(t) },
+		"memory": func(t *testing.T) Store { return NewMemoryStore() },
+	}
+	for name, newStore := range stores {
+		newStore := newStore
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			test(t, newStore(t))
+		})
+	}
+}
+
+func TestStoreImportSelectsAccount(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		require.NoError(t, store.ImportAccount(Account{Label: "first"}))
+		require.NoError(t, store.ImportAccount(Account{Label: "second"}))
+		require.ErrorIs(t, store.ImportAccount(Account{Label: "first"}), ErrAccountExists)
+
+		selected, err := store.GetSelectedAccount()
+		require.NoError(t, err)
+		require.Equal(t, "second", selected.Label)
+		require.True(t, selected.Selected)
+
+		accounts, err := store.ListAccounts()
+		require.NoError(t, err)
+		require.Len(t, accounts, 2)
+		require.Equal(t, "first", accounts[0].Label)
+		require.False(t, accounts[0].Selected)
+		require.True(t, accounts[1].Selected)
+	})
+}
+
+func TestStoreSelectMissingKeepsSelection(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		_, err := store.GetSelectedNetwork()
+		require.ErrorIs(t, err, ErrNetworkNotSelected)
+
+		require.NoError(t, store.AddNetwork(Network{Label: "mainnet"}))
+		require.ErrorIs(t, store.SelectNetwork("missing"), ErrNetworkNotFound)
+
+		selected, err := store.GetSelectedNetwork()
+		require.NoError(t, err)
+		require.Equal(t, "mainnet", selected.Label)
+	})
+}
+
+func TestStoreRemoveReselects(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		require.NoError(t, store.ImportAccount(Account{Label: "a"}))
+		require.NoError(t, store.ImportAccount(Account{Label: "b"}))
+		require.NoError(t, store.ImportAccount(Account{Label: "c"}))
+
+		// Removing an account that is not selected keeps the selection
+		require.NoError(t, store.RemoveAccount("b"))
+		selected, err := store.GetSelectedAccount()
+		require.NoError(t, err)
+		require.Equal(t, "c", selected.Label)
+
+		// Removing the selected account selects the first remaining one
+		require.NoError(t, store.RemoveAccount("c"))
+		selected, err = store.GetSelectedAccount()
+		require.NoError(t, err)
+		require.Equal(t, "a", selected.Label)
+
+		require.NoError(t, store.RemoveAccount("a"))
+		_, err = store.GetSelectedAccount()
+		require.ErrorIs(t, err, ErrAccountNotSelected)
+
+		require.ErrorIs(t, store.RemoveAccount("a"), ErrAccountNotFound)
+	})
+}
+
+func TestStoreArchive(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		require.NoError(t, store.AddNetwork(Network{Label: "mainnet"}))
+		require.NoError(t, store.AddNetwork(Network{Label: "sepolia"}))
+
+		// Archiving the selected network selects another one
+		require.NoError(t, store.ArchiveNetwork("sepolia"))
+		selected, err := store.GetSelectedNetwork()
+		require.NoError(t, err)
+		require.Equal(t, "mainnet", selected.Label)
+		require.ErrorIs(t, store.ArchiveNetwork("sepolia"), ErrNetworkNotFound)
+
+		archived, err := store.ListArchivedNetworks()
+		require.NoError(t, err)
+		require.Len(t, archived, 1)
+		require.Equal(t, "sepolia", archived[0].Label)
+
+		// A network added under the archived label blocks restoring it
+		require.NoError(t, store.AddNetwork(Network{Label: "sepolia"}))
+		require.ErrorIs(t, store.UnarchiveNetwork("sepolia"), ErrNetworkExists)
+		require.NoError(t, store.DeleteNetwork("sepolia"))
+		require.NoError(t, store.UnarchiveNetwork("sepolia"))
+		require.ErrorIs(t, store.UnarchiveNetwork("sepolia"), ErrNetworkNotFound)
+
+		require.NoError(t, store.ImportAccount(Account{Label: "a", Publicy: "0x01"}))
+		require.NoError(t, store.ArchiveAccount("a"))
+		_, err = store.GetSelectedAccount()
+		require.ErrorIs(t, err, ErrAccountNotSelected)
+		accounts, err := store.ListArchivedAccounts()
+		require.NoError(t, err)
+		require.Equal(t, []Account{{Label: "a", Publicy: "0x01"}}, accounts)
+
+		require.NoError(t, store.UnarchiveAccount("a"))
+		account, err := store.GetAccount("a")
+		require.NoError(t, err)
+		require.False(t, account.Selected)
+	})
+}
+
+func TestStoreTransaction(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		require.NoError(t, store.AddNetwork(Network{Label: "mainnet"}))
+
+		// A failing transaction keeps none of its changes
+		err := store.Transaction(func(store Store) error {
+			require.NoError(t, store.AddNetwork(Network{Label: "sepolia"}))
+			require.NoError(t, store.ImportAccount(Account{Label: "a"}))
+			_, err := store.GetNetwork("sepolia")
+			require.NoError(t, err)
+			return store.AddNetwork(Network{Label: "mainnet"})
+		})
+		require.ErrorIs(t, err, ErrNetworkExists)
+
+		networks, err := store.ListNetworks()
+		require.NoError(t, err)
+		require.Len(t, networks, 1)
+		require.True(t, networks[0].Selected)
+		_, err = store.GetAccount("a")
+		require.ErrorIs(t, err, ErrAccountNotFound)
+
+		require.NoError(t, store.Transaction(func(store Store) error {
+			return store.AddNetwork(Network{Label: "sepolia"})
+		}))
+		selected, err := store.GetSelectedNetwork()
+		require.NoError(t, err)
+		require.Equal(t, "sepolia", selected.Label)
+	})
+}
+
+func TestStoreUpdate(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		require.NoError(t, store.AddNetwork(Network{Label: "mainnet"}))
+		require.NoError(t, store.UpdateNetwork("mainnet", func(network *Network) error {
+			require.True(t, network.Selected)
+			network.Symbol = "ETH"
+			return nil
+		}))
+
+		network, err := store.GetNetwork("mainnet")
+		require.NoError(t, err)
+		require.Equal(t, "ETH", network.Symbol)
+		require.True(t, network.Selected)
+
+		require.ErrorIs(t, store.UpdateAccount("missing", func(account *Account) error { return nil }), ErrAccountNotFound)
+	})
+}
+
+func TestStoreRenameNetwork(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		require.NoError(t, store.AddNetwork(Network{Label: "mainnet"}))
+		require.NoError(t, store.AddNetwork(Network{Label: "sepolia"}))
+		require.NoError(t, store.SelectNetwork("mainnet"))
+		require.NoError(t, store.ImportAccount(Account{Label: "a", MultiTokens: map[string][]string{"mainnet": {"0x01"}, "sepolia": {"0x02"}}}))
+
+		require.ErrorIs(t, store.UpdateNetwork("mainnet", func(network *Network) error {
+			network.Label = "sepolia"
+			return nil
+		}), ErrNetworkExists)
+
+		require.NoError(t, store.UpdateNetwork("mainnet", func(network *Network) error {
+			network.Label = "ethereum"
+			return nil
+		}))
+
+		_, err := store.GetNetwork("mainnet")
+		require.ErrorIs(t, err, ErrNetworkNotFound)
+		selected, err := store.GetSelectedNetwork()
+		require.NoError(t, err)
+		require.Equal(t, "ethereum", selected.Label)
+
+		account, err := store.GetAccount("a")
+		require.NoError(t, err)
+		require.Equal(t, map[string][]string{"ethereum": {"0x01"}, "sepolia": {"0x02"}}, account.MultiTokens)
+	})
+}
+
+func TestBoltStoreDoesNotPersistSelected(t *testing.T) {
+	store := newTestBoltStore(t)
+	require.NoError(t, store.AddNetwork(Network{Label: "mainnet"}))
+
+	err := store.db.View(func(tx *bolt.Tx) error {
+		require.NotContains(t, string(tx.Bucket(networksBucket).Get([]byte("mainnet"))), "elected")
+		return nil
+	})
+	require.NoError(t, err)
+}
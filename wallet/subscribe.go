@@ -0,0 +1,108 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// pollInterval is how often endpoints without subscriptions are polled.
+var pollInterval = time.Second
+
+// onNewBlock calls check once and then for every new block until it reports
+// done, returns an error or ctx is cancelled. WebSocket and IPC endpoints are
+// subscribed to new heads; HTTP endpoints are polled.
+func onNewBlock(ctx context.Context, client *ethclient.Client, check func() (bool, error)) error {
+	done, err := check()
+	if err != nil || done {
+		return err
+	}
+
+	var (
+		heads  <-chan *types.Header
+		subErr <-chan error
+		ticks  <-chan time.Time
+	)
+	if client.Client().SupportsSubscriptions() {
+		ch := make(chan *types.Header)
+		sub, err := client.SubscribeNewHead(ctx, ch)
+		if err != nil {
+			return fmt.Errorf("failed to subscribe to new blocks: %v", err)
+		}
+		defer sub.Unsubscribe()
+		heads, subErr = ch, sub.Err()
+	} else {
+		ticker := time.NewTicker(pollInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-subErr:
+			return fmt.Errorf("subscription to new blocks failed: %v", err)
+		case <-heads:
+		case <-ticks:
+		}
+
+		done, err := check()
+		if err != nil || done {
+			return err
+		}
+	}
+}
+
+// waitForReceipt blocks until the receipt for the given transaction is
+// available.
+func waitForReceipt(client *ethclient.Client, hash common.Hash) (*types.Receipt, error) {
+	var receipt *types.Receipt
+	err := onNewBlock(context.Background(), client, func() (bool, error) {
+		var err error
+		receipt, err = client.TransactionReceipt(context.Background(), hash)
+		if errors.Is(err, ethereum.NotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to get transaction receipt: %v", err)
+		}
+		return true, nil
+	})
+	return receipt, err
+}
+
+// WatchBalance calls update with the balance of address and then again every
+// time it changes, until ctx is cancelled or update returns an error.
+func WatchBalance(ctx context.Context, address string, network Network, update func(*big.Int) error) error {
+	client, err := dialNetwork(network)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	account := common.HexToAddress(address)
+	var last *big.Int
+	err = onNewBlock(ctx, client, func() (bool, error) {
+		balance, err := client.BalanceAt(ctx, account, nil)
+		if err != nil {
+			return false, fmt.Errorf("failed to get balance: %v", err)
+		}
+		if last != nil && last.Cmp(balance) == 0 {
+			return false, nil
+		}
+		last = balance
+		return false, update(balance)
+	})
+	if errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,54 @@
+package wallet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchBalancePolling(t *testing.T) {
+	pollInterval = 10 * time.Millisecond
+
+	// The balance is 1 wei for the first two calls and 2 wei afterwards
+	var calls atomic.Int32
+	server := newRPCServer(t, func(method string) string {
+		if method == "eth_chainId" {
+			return `"0x1"`
+		}
+		if method != "eth_getBalance" {
+			return ""
+		}
+		if calls.Add(1) <= 2 {
+			return `"0x1"`
+		}
+		return `"0x2"`
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	var balances []string
+	err := WatchBalance(ctx, "0x0000000000000000000000000000000000000001", Network{ChainId: 1, RpcUrl: server.URL}, func(balance *big.Int) error {
+		balances = append(balances, balance.String())
+		if len(balances) == 2 {
+			cancel()
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"1", "2"}, balances)
+}
+
+func TestValidateRpcUrl(t *testing.T) {
+	for _, rpcUrl := range []string{"http://localhost:8545", "https://rpc.example.org", "ws://localhost:8546", "wss://rpc.example.org", "/tmp/geth.ipc"} {
+		require.NoError(t, ValidateRpcUrl(rpcUrl), rpcUrl)
+	}
+	for _, rpcUrl := range []string{"", "localhost:8545", "ftp://example.org", "geth.ipc"} {
+		require.Error(t, ValidateRpcUrl(rpcUrl), fmt.Sprintf("%q", rpcUrl))
+	}
+}
@@ -2,12 +2,12 @@ package wallet
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"fmt"
-	"math"
 	"math/big"
 	"strings"
-	"time"
 
+	"github.com/EliasManj/go-wallet/utils"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -16,8 +16,8 @@ import (
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-// ERC-20 ABI for balanceOf function
-const erc20ABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"}]`
+// ERC-20 ABI for balanceOf and transfer functions
+const erc20ABI = `[{"constant":true,"inputs":[{"name":"_owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"balance","type":"uint256"}],"payable":false,"stateMutability":"view","type":"function"},{"constant":false,"inputs":[{"name":"_to","type":"address"},{"name":"_value","type":"uint256"}],"name":"transfer","outputs":[{"name":"","type":"bool"}],"payable":false,"stateMutability":"nonpayable","type":"function"}]`
 
 type Transaction struct {
 	From     string
@@ -29,32 +29,27 @@ type Transaction struct {
 	GasPrice *big.Int
 }
 
+// GetBalance returns the balance in wei of address, failing over to the
+// next endpoint of the network when one cannot be reached.
 func GetBalance(address string, network Network) (*big.Int, error) {
-	client, err := ethclient.Dial(network.RpcUrl)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
-	}
-	defer client.Close()
-
 	// Convert the address to a common.Address type
 	account := common.HexToAddress(address)
 
 	// Get the balance of the account at the latest block
-	balance, err := client.BalanceAt(context.Background(), account, nil)
+	var balance *big.Int
+	err := withEndpoints(network, func(client *ethclient.Client) error {
+		var err error
+		balance, err = client.BalanceAt(context.Background(), account, nil)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get balance: %v", err)
+		return nil, fmt.Errorf("failed to get balance: %w", err)
 	}
 
 	return balance, nil
 }
 
 func GetTokenBalance(tokenAddress string, ownerAddress string, network Network) (*big.Int, error) {
-	client, err := ethclient.Dial(network.RpcUrl)
-	if err != nil {
-		return nil, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
-	}
-	defer client.Close()
-
 	// Convert the addresses to common.Address type
 	token := common.HexToAddress(tokenAddress)
 	owner := common.HexToAddress(ownerAddress)
@@ -71,13 +66,18 @@ func GetTokenBalance(tokenAddress string, ownerAddress string, network Network)
 		return nil, fmt.Errorf("failed to pack data for balanceOf call: %v", err)
 	}
 
-	// Perform the call
-	result, err := client.CallContract(context.Background(), ethereum.CallMsg{
-		To:   &token,
-		Data: callData,
-	}, nil)
+	// Perform the call, failing over to the next endpoint if needed
+	var result []byte
+	err = withEndpoints(network, func(client *ethclient.Client) error {
+		var err error
+		result, err = client.CallContract(context.Background(), ethereum.CallMsg{
+			To:   &token,
+			Data: callData,
+		}, nil)
+		return err
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to call contract: %v", err)
+		return nil, fmt.Errorf("failed to call contract: %w", err)
 	}
 
 	// Unpack the result into a big.Int
@@ -90,11 +90,36 @@ func GetTokenBalance(tokenAddress string, ownerAddress string, network Network)
 	return balance, nil
 }
 
-// SendETH sends Ether from one account to another.
-func SendETH(fromPrivateKey string, toAddress string, ethAmount *big.Float, network Network) (Transaction, error) {
-	client, err := ethclient.Dial(network.RpcUrl)
+// IsContract reports whether code is deployed at address on network.
+func IsContract(address string, network Network) (bool, error) {
+	var code []byte
+	err := withEndpoints(network, func(client *ethclient.Client) error {
+		var err error
+		code, err = client.CodeAt(context.Background(), common.HexToAddress(address), nil)
+		return err
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to get code: %w", err)
+	}
+	return len(code) > 0, nil
+}
+
+// SendETH sends Ether from one account to another. The amount is a decimal
+// number of ether, or any amount accepted by utils.ParseAmount such as
+// "20gwei", and is converted to wei exactly.
+func SendETH(fromPrivateKey string, toAddress string, ethAmount string, network Network) (Transaction, error) {
+	weiAmount, err := utils.ParseAmount(ethAmount, "ether")
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	return SendWei(fromPrivateKey, toAddress, weiAmount, network)
+}
+
+func SendWei(fromPrivateKey string, toAddress string, amount *big.Int, network Network) (Transaction, error) {
+	client, err := dialNetwork(network)
 	if err != nil {
-		return Transaction{}, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
+		return Transaction{}, err
 	}
 	defer client.Close()
 
@@ -109,12 +134,6 @@ func SendETH(fromPrivateKey string, toAddress string, ethAmount *big.Float, netw
 		return Transaction{}, fmt.Errorf("invalid private key: %v", err)
 	}
 
-	// Convert the ETH amount to wei
-
-	weiAmount := new(big.Int)
-	ethToWei := new(big.Float).Mul(ethAmount, big.NewFloat(math.Pow10(18))) // ETH to wei conversion
-	ethToWei.Int(weiAmount)                                                 // Store the result in a big.Int
-
 	// Get the public address of the sender
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
@@ -128,44 +147,41 @@ func SendETH(fromPrivateKey string, toAddress string, ethAmount *big.Float, netw
 		return Transaction{}, fmt.Errorf("failed to get gas price: %v", err)
 	}
 
-	// Set gas limit
-	gasLimit := uint64(21000) // basic transaction
+	// Estimate the gas limit, which is more than a basic transfer when the
+	// recipient is a contract
+	gasLimit, err := estimateTransferGas(client, fromAddress, common.HexToAddress(toAddress), amount)
+	if err != nil {
+		return Transaction{}, err
+	}
 
 	// Create the transaction
-	tx := types.NewTransaction(nonce, common.HexToAddress(toAddress), weiAmount, gasLimit, gasPrice, nil)
+	tx := types.NewTransaction(nonce, common.HexToAddress(toAddress), amount, gasLimit, gasPrice, nil)
 
 	// Sign the transaction with the sender's private key
-	chainID := big.NewInt(int64(network.ChainId))
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signTx(tx, privateKey, network)
 	if err != nil {
-		return Transaction{}, fmt.Errorf("failed to sign transaction: %v", err)
+		return Transaction{}, err
 	}
 
 	// Send the transaction
-	err = client.SendTransaction(context.Background(), signedTx)
+	err = sendTransaction(client, network, signedTx)
 	if err != nil {
-		return Transaction{}, fmt.Errorf("failed to send transaction: %v", err)
+		return Transaction{}, fmt.Errorf("failed to send transaction: %w", err)
 	}
 
-	// Retry mechanism for fetching the receipt
-	var receipt *types.Receipt
-	for {
-		receipt, err = client.TransactionReceipt(context.Background(), signedTx.Hash())
-		if err == nil {
-			break
-		}
-		if err.Error() == "not found" {
-			time.Sleep(time.Second * 1)
-			continue
-		}
-		return Transaction{}, fmt.Errorf("failed to get transaction receipt: %v", err)
+	receipt, err := waitForReceipt(client, signedTx.Hash())
+	if err != nil {
+		return Transaction{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTransactionReverted, signedTx.Hash().Hex())
 	}
 
 	// Return the transaction details including gas used and gas price
 	return Transaction{
 		From:     fromAddress.String(),
 		To:       toAddress,
-		Amount:   weiAmount,
+		Amount:   amount,
 		Network:  network,
 		Hash:     signedTx.Hash().Hex(),
 		GasUsed:  new(big.Int).SetUint64(receipt.GasUsed),
@@ -173,71 +189,125 @@ func SendETH(fromPrivateKey string, toAddress string, ethAmount *big.Float, netw
 	}, nil
 }
 
-func SendWei(fromPrivateKey string, toAddress string, amount *big.Int, network Network) (Transaction, error) {
-	client, err := ethclient.Dial(network.RpcUrl)
+// SendToken transfers an amount of an ERC-20 token, in the token's smallest unit.
+func SendToken(fromPrivateKey string, tokenAddress string, toAddress string, amount *big.Int, network Network) (Transaction, error) {
+	client, err := dialNetwork(network)
 	if err != nil {
-		return Transaction{}, fmt.Errorf("failed to connect to the Ethereum client: %v", err)
+		return Transaction{}, err
 	}
 	defer client.Close()
 
-	// Remove "0x" prefix from the private key
-	if len(fromPrivateKey) > 2 && fromPrivateKey[:2] == "0x" {
-		fromPrivateKey = fromPrivateKey[2:]
+	privateKey, err := parsePrivateKey(fromPrivateKey)
+	if err != nil {
+		return Transaction{}, err
 	}
 
-	// Convert the private key string to ecdsa.PrivateKey
-	privateKey, err := crypto.HexToECDSA(fromPrivateKey)
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
 	if err != nil {
-		return Transaction{}, fmt.Errorf("invalid private key: %v", err)
+		return Transaction{}, fmt.Errorf("failed to parse ERC-20 ABI: %v", err)
 	}
 
-	// Get the public address of the sender
+	callData, err := parsedABI.Pack("transfer", common.HexToAddress(toAddress), amount)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to pack data for transfer call: %v", err)
+	}
+
+	tx, err := sendContractTx(client, privateKey, common.HexToAddress(tokenAddress), callData, network)
+	if err != nil {
+		return Transaction{}, err
+	}
+	tx.Amount = amount
+	return tx, nil
+}
+
+// estimateTransferGas estimates the gas limit of sending value to an address.
+// It is 21000 for an account and more for a contract.
+func estimateTransferGas(client *ethclient.Client, from common.Address, to common.Address, value *big.Int) (uint64, error) {
+	gasLimit, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
+		From:  from,
+		To:    &to,
+		Value: value,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas: %w", nodeError(err))
+	}
+	return gasLimit, nil
+}
+
+// MaxSendable returns the largest amount of wei a transfer with the given gas
+// limit can move out of balance at the given gas price, which is the balance
+// minus the fee. It fails if the balance does not cover the fee.
+func MaxSendable(balance *big.Int, gasPrice *big.Int, gasLimit uint64) (*big.Int, error) {
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(gasLimit), gasPrice)
+	if balance.Cmp(fee) <= 0 {
+		return nil, fmt.Errorf("%w: balance of %s wei does not cover the fee of %s wei", ErrInsufficientFunds, balance, fee)
+	}
+	return new(big.Int).Sub(balance, fee), nil
+}
+
+// SendMaxETH transfers the whole balance of the account, net of the fee of
+// the transfer itself, so that no dust is left behind.
+func SendMaxETH(fromPrivateKey string, toAddress string, network Network) (Transaction, error) {
+	client, err := dialNetwork(network)
+	if err != nil {
+		return Transaction{}, err
+	}
+	defer client.Close()
+
+	privateKey, err := parsePrivateKey(fromPrivateKey)
+	if err != nil {
+		return Transaction{}, err
+	}
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
 	if err != nil {
 		return Transaction{}, fmt.Errorf("failed to get nonce: %v", err)
 	}
 
-	// Set gas price
 	gasPrice, err := client.SuggestGasPrice(context.Background())
 	if err != nil {
 		return Transaction{}, fmt.Errorf("failed to get gas price: %v", err)
 	}
 
-	// Set gas limit
-	gasLimit := uint64(21000) // basic transaction
+	// Use the pending balance so that queued transactions are accounted for
+	balance, err := client.PendingBalanceAt(context.Background(), fromAddress)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to get balance: %v", err)
+	}
+
+	// The amount is not known before the fee, so the gas is estimated for a
+	// transfer of one wei, which takes the same code path of the recipient
+	gasLimit, err := estimateTransferGas(client, fromAddress, common.HexToAddress(toAddress), big.NewInt(1))
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	amount, err := MaxSendable(balance, gasPrice, gasLimit)
+	if err != nil {
+		return Transaction{}, err
+	}
 
-	// Create the transaction
 	tx := types.NewTransaction(nonce, common.HexToAddress(toAddress), amount, gasLimit, gasPrice, nil)
 
-	// Sign the transaction with the sender's private key
-	chainID := big.NewInt(int64(network.ChainId))
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	signedTx, err := signTx(tx, privateKey, network)
 	if err != nil {
-		return Transaction{}, fmt.Errorf("failed to sign transaction: %v", err)
+		return Transaction{}, err
 	}
 
-	// Send the transaction
-	err = client.SendTransaction(context.Background(), signedTx)
+	err = sendTransaction(client, network, signedTx)
 	if err != nil {
-		return Transaction{}, fmt.Errorf("failed to send transaction: %v", err)
+		return Transaction{}, fmt.Errorf("failed to send transaction: %w", err)
 	}
 
-	// Retry mechanism for fetching the receipt
-	var receipt *types.Receipt
-	for {
-		receipt, err = client.TransactionReceipt(context.Background(), signedTx.Hash())
-		if err == nil {
-			break
-		}
-		if err.Error() == "not found" {
-			time.Sleep(time.Second * 1)
-			continue
-		}
-		return Transaction{}, fmt.Errorf("failed to get transaction receipt: %v", err)
+	receipt, err := waitForReceipt(client, signedTx.Hash())
+	if err != nil {
+		return Transaction{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTransactionReverted, signedTx.Hash().Hex())
 	}
 
-	// Return the transaction details including gas used and gas price
 	return Transaction{
 		From:     fromAddress.String(),
 		To:       toAddress,
@@ -265,3 +335,73 @@ func GetAddressFromPrivateKey(privateKey string) (string, error) {
 	fromAddress := crypto.PubkeyToAddress(privateKeyECDSA.PublicKey)
 	return fromAddress.String(), nil
 }
+
+// parsePrivateKey converts a hex private key, with or without the "0x"
+// prefix, to an ecdsa.PrivateKey.
+func parsePrivateKey(privateKey string) (*ecdsa.PrivateKey, error) {
+	if len(privateKey) > 2 && privateKey[:2] == "0x" {
+		privateKey = privateKey[2:]
+	}
+
+	privateKeyECDSA, err := crypto.HexToECDSA(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid private key: %v", err)
+	}
+	return privateKeyECDSA, nil
+}
+
+// sendContractTx signs and sends a call to a contract method, estimating the
+// gas limit and waiting for the receipt. A reverted transaction is reported
+// as an error.
+func sendContractTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, contract common.Address, data []byte, network Network) (Transaction, error) {
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to get nonce: %v", err)
+	}
+
+	gasPrice, err := client.SuggestGasPrice(context.Background())
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to get gas price: %v", err)
+	}
+
+	gasLimit, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
+		From: fromAddress,
+		To:   &contract,
+		Data: data,
+	})
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to estimate gas: %w", nodeError(err))
+	}
+
+	tx := types.NewTransaction(nonce, contract, big.NewInt(0), gasLimit, gasPrice, data)
+
+	signedTx, err := signTx(tx, privateKey, network)
+	if err != nil {
+		return Transaction{}, err
+	}
+
+	err = sendTransaction(client, network, signedTx)
+	if err != nil {
+		return Transaction{}, fmt.Errorf("failed to send transaction: %w", err)
+	}
+
+	receipt, err := waitForReceipt(client, signedTx.Hash())
+	if err != nil {
+		return Transaction{}, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return Transaction{}, fmt.Errorf("%w: %s", ErrTransactionReverted, signedTx.Hash().Hex())
+	}
+
+	return Transaction{
+		From:     fromAddress.String(),
+		To:       contract.String(),
+		Amount:   big.NewInt(0),
+		Network:  network,
+		Hash:     signedTx.Hash().Hex(),
+		GasUsed:  new(big.Int).SetUint64(receipt.GasUsed),
+		GasPrice: gasPrice,
+	}, nil
+}
@@ -1,15 +1,19 @@
 package wallet
 
 import (
+	"encoding/hex"
 	"math/big"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/EliasManj/go-wallet/utils"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/stretchr/testify/require"
 )
 
 func TestGetBalanceNewAccount(t *testing.T) {
-	account, err := CreateNewAccount(db, "testgetbalance")
+	account, err := CreateNewAccount(store, "testgetbalance")
 	require.NoError(t, err)
 	network := Network{Label: utils.CreateAccountLabel(), ChainId: 31337, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
 	balance, err := GetBalance(account.Publicy, network)
@@ -23,7 +27,7 @@ func TestGetBalanceAccountWithFunds(t *testing.T) {
 		Publicy: "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
 		Label:   utils.CreateNetworkLabel(),
 	}
-	err := ImportAccount(db, account)
+	err := ImportAccount(store, account)
 	require.NoError(t, err)
 	network := Network{Label: "test", ChainId: 31337, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
 	balance, err := GetBalance(account.Publicy, network)
@@ -42,7 +46,7 @@ func TestGetBalanceAfterSendingTransaction(t *testing.T) {
 		Publicy: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
 		Label:   utils.CreateNetworkLabel("to"),
 	}
-	err := ImportAccount(db, from)
+	err := ImportAccount(store, from)
 	require.NoError(t, err)
 	network := Network{Label: "test", ChainId: 31337, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
 
@@ -69,7 +73,7 @@ func TestGetBalanceAfterSendingTransaction(t *testing.T) {
 }
 
 func TestSendEthAndConvertToWei(t *testing.T) {
-	eth := 99.0
+	eth := "99"
 	from := Account{
 		Publicy:  "0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266",
 		Privatey: "0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80",
@@ -79,16 +83,16 @@ func TestSendEthAndConvertToWei(t *testing.T) {
 		Publicy: "0x70997970C51812dc3A010C7d01b50e0d17dc79C8",
 		Label:   utils.CreateNetworkLabel("to"),
 	}
-	err := ImportAccount(db, from)
+	err := ImportAccount(store, from)
 	require.NoError(t, err)
 	network := Network{Label: "test", ChainId: 31337, Symbol: "ETH", RpcUrl: "http://localhost:8545"}
 
 	initialBalance, err := GetBalance(from.Publicy, network)
 	require.NoError(t, err)
 
-	amountToSend := new(big.Float).SetFloat64(eth)
-	amountToSendInWei := utils.EthToWei(amountToSend)
-	tx, err := SendETH(from.Privatey, to.Publicy, amountToSend, network)
+	amountToSendInWei, err := utils.ParseAmount(eth, "ether")
+	require.NoError(t, err)
+	tx, err := SendETH(from.Privatey, to.Publicy, eth, network)
 	require.NoError(t, err)
 	require.Equal(t, from.Publicy, tx.From)
 	require.Equal(t, to.Publicy, tx.To)
@@ -107,3 +111,70 @@ func TestSendEthAndConvertToWei(t *testing.T) {
 	require.Equal(t, expectedFinalBalance, finalBalance)
 
 }
+
+func TestMaxSendable(t *testing.T) {
+	gasPrice := big.NewInt(1000000000)
+	fee := new(big.Int).Mul(big.NewInt(21000), gasPrice)
+
+	balance := new(big.Int).Add(fee, big.NewInt(12345))
+	amount, err := MaxSendable(balance, gasPrice, 21000)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(12345), amount)
+
+	_, err = MaxSendable(fee, gasPrice, 21000)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = MaxSendable(big.NewInt(0), gasPrice, 21000)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// A contract recipient needs more gas, which leaves less to send
+	_, err = MaxSendable(balance, gasPrice, 30000)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+	balance.Add(balance, new(big.Int).Mul(big.NewInt(9000), gasPrice))
+	amount, err = MaxSendable(balance, gasPrice, 30000)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(12345), amount)
+}
+
+// newTransferServer answers the calls of a transfer and reports its receipt
+// with status, estimating gasLimit.
+func newTransferServer(t *testing.T, gasLimit string, status string) *httptest.Server {
+	return newRPCServer(t, func(method string) string {
+		switch method {
+		case "eth_chainId":
+			return `"0x1"`
+		case "eth_getTransactionCount":
+			return `"0x0"`
+		case "eth_gasPrice":
+			return `"0x1"`
+		case "eth_estimateGas":
+			return `"` + gasLimit + `"`
+		case "eth_getBalance":
+			return `"0xf4240"`
+		case "eth_sendRawTransaction":
+			return `"0x0000000000000000000000000000000000000000000000000000000000000001"`
+		case "eth_getTransactionReceipt":
+			return `{"status":"` + status + `","cumulativeGasUsed":"0x7530","gasUsed":"0x7530","logs":[],"logsBloom":"0x` + strings.Repeat("00", 256) + `","transactionHash":"0x0000000000000000000000000000000000000000000000000000000000000001"}`
+		}
+		return ""
+	})
+}
+
+func TestSendRevertedTransfer(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	privateKey := hex.EncodeToString(crypto.FromECDSA(key))
+	to := "0x0000000000000000000000000000000000000002"
+
+	network := Network{ChainId: 1, RpcUrl: newTransferServer(t, "0x7530", "0x0").URL}
+	_, err = SendWei(privateKey, to, big.NewInt(1), network)
+	require.ErrorIs(t, err, ErrTransactionReverted)
+	_, err = SendMaxETH(privateKey, to, network)
+	require.ErrorIs(t, err, ErrTransactionReverted)
+
+	// The sweep is net of the estimated fee, not of a plain transfer's
+	network = Network{ChainId: 1, RpcUrl: newTransferServer(t, "0x7530", "0x1").URL}
+	tx, err := SendMaxETH(privateKey, to, network)
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(1000000-30000), tx.Amount)
+}